@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"runtime/debug"
+)
+
+func init() {
+	register(&command{
+		name:    "about",
+		summary: "print build information about this binary",
+		run:     runAbout,
+	})
+}
+
+func runAbout(args []string) error {
+	fs := flag.NewFlagSet("about", flag.ContinueOnError)
+	sbom := fs.Bool("sbom", false, "print embedded module versions and build provenance")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return fmt.Errorf("binary carries no build info (built without module support?)")
+	}
+
+	fmt.Printf("%s for %s\n", brandProduct, org().Name)
+	fmt.Println("go:      " + info.GoVersion)
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			fmt.Println("commit:  " + s.Value)
+		case "vcs.time":
+			fmt.Println("built:   " + s.Value)
+		case "vcs.modified":
+			if s.Value == "true" {
+				fmt.Println("         (built from a modified tree)")
+			}
+		}
+	}
+	if !*sbom {
+		return nil
+	}
+
+	fmt.Println("\nmodules:")
+	fmt.Printf("  %s %s\n", info.Main.Path, moduleVersion(info.Main))
+	for _, dep := range info.Deps {
+		fmt.Printf("  %s %s\n", dep.Path, moduleVersion(*dep))
+	}
+	fmt.Println("\nbuild settings:")
+	for _, s := range info.Settings {
+		fmt.Printf("  %s=%s\n", s.Key, s.Value)
+	}
+	return nil
+}
+
+func moduleVersion(m debug.Module) string {
+	v := m.Version
+	if v == "" || v == "(devel)" {
+		v = "(devel)"
+	}
+	if m.Sum != "" {
+		v += " " + m.Sum
+	}
+	return v
+}
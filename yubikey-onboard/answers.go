@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Prompt answer files make runs reproducible: a JSON object mapping the
+// exact question text to the reply. Recording a user's session gives a
+// replayable artifact for bug reports; feeding one back scripts the
+// whole flow.
+var (
+	promptAnswers   map[string]string
+	recordedAnswers map[string]string
+)
+
+func loadAnswers(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &promptAnswers); err != nil {
+		return fmt.Errorf("answers file %s: %v", path, err)
+	}
+	return nil
+}
+
+// answerFor returns the canned reply for a question, if any. Secrets
+// may be supplied this way too, at the author's own risk.
+func answerFor(question string) (string, bool) {
+	if promptAnswers == nil {
+		return "", false
+	}
+	v, ok := promptAnswers[question]
+	return v, ok
+}
+
+// recordAnswer captures one question/answer pair when recording is on.
+// Secrets are never recorded.
+func recordAnswer(question, answer string) {
+	if recordedAnswers == nil {
+		return
+	}
+	recordedAnswers[question] = answer
+}
+
+func startRecordingAnswers() {
+	recordedAnswers = map[string]string{}
+}
+
+func saveRecordedAnswers(path string) error {
+	data, err := json.MarshalIndent(recordedAnswers, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0o600)
+}
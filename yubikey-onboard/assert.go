@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	register(&command{
+		name:    "assert",
+		summary: "evaluate compliance assertions, one pass/fail line each",
+		run:     runAssert,
+	})
+}
+
+// assertions maps assertion names to evaluators. Each takes the
+// expected value and returns nil on pass or a reason on fail, so
+// posture-check scripts can gate on the exit code without parsing JSON.
+var assertions = map[string]func(want string) error{
+	"key-expiry-days-gt": assertKeyExpiryDaysGt,
+	"touch-policy":       assertTouchPolicy,
+	"commit-signing":     assertCommitSigning,
+}
+
+func runAssert(args []string) error {
+	if len(args) == 0 {
+		names := make([]string, 0, len(assertions))
+		for name := range assertions {
+			names = append(names, name)
+		}
+		return fmt.Errorf("usage: assert name=value...; known assertions: %s", strings.Join(names, ", "))
+	}
+	r, err := newRenderer()
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
+		name, want, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("malformed assertion %q (want name=value)", arg)
+		}
+		eval, known := assertions[name]
+		if !known {
+			r.result(arg, fmt.Errorf("unknown assertion"))
+			continue
+		}
+		r.result(arg, eval(want))
+	}
+	if failed := r.close(); failed > 0 {
+		return fmt.Errorf("%d of %d assertions failed", failed, len(args))
+	}
+	return nil
+}
+
+// assertKeyExpiryDaysGt checks the primary key expires more than N days
+// out. A key that never expires passes.
+func assertKeyExpiryDaysGt(want string) error {
+	minDays, err := strconv.Atoi(want)
+	if err != nil {
+		return fmt.Errorf("want a day count, got %q", want)
+	}
+	id, err := primaryIdentity()
+	if err != nil {
+		return err
+	}
+	out, err := output("gpg", "--list-keys", "--with-colons", id.fingerprint)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		f := strings.Split(line, ":")
+		if f[0] != "pub" || len(f) < 7 {
+			continue
+		}
+		if f[6] == "" {
+			return nil // no expiry set
+		}
+		epoch, err := strconv.ParseInt(f[6], 10, 64)
+		if err != nil {
+			return err
+		}
+		days := int(time.Unix(epoch, 0).Sub(now()).Hours() / 24)
+		if days <= minDays {
+			return fmt.Errorf("key expires in %d days", days)
+		}
+		return nil
+	}
+	return fmt.Errorf("no pub record for %s", id.fingerprint)
+}
+
+// assertTouchPolicy checks the signature slot's touch policy. Accepted
+// values match ykman's: on, off, fixed, cached.
+func assertTouchPolicy(want string) error {
+	out, err := output("ykman", "openpgp", "info")
+	if err != nil {
+		return fmt.Errorf("ykman unavailable: %w", err)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		l := strings.ToLower(strings.TrimSpace(line))
+		if !strings.HasPrefix(l, "signature:") && !strings.HasPrefix(l, "sig:") {
+			continue
+		}
+		_, v, _ := strings.Cut(l, ":")
+		got := strings.TrimSpace(v)
+		if got != strings.ToLower(want) {
+			return fmt.Errorf("signature touch policy is %q", got)
+		}
+		return nil
+	}
+	return fmt.Errorf("could not find the signature touch policy in ykman output")
+}
+
+func assertCommitSigning(want string) error {
+	out, _ := output("git", "config", "--global", "commit.gpgsign")
+	got := strings.TrimSpace(out)
+	if got == "" {
+		got = "false"
+	}
+	if got != want {
+		return fmt.Errorf("commit.gpgsign is %q", got)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+func init() {
+	register(&command{
+		name:    "auth",
+		summary: "log in to services and store tokens in the keyring",
+		run:     runAuth,
+	})
+}
+
+func runAuth(args []string) error {
+	if len(args) == 2 && args[0] == "login" && args[1] == "gitea" {
+		return authLoginGitea()
+	}
+	return fmt.Errorf("usage: auth login gitea")
+}
+
+func authLoginGitea() error {
+	fmt.Printf("Create a token at %s/user/settings/applications\n", giteaURL())
+	fmt.Println("(scopes: read:user and write:public_key)")
+	token, err := readSecret("Paste the token:")
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return fmt.Errorf("no token given")
+	}
+	login, err := giteaWhoami(token)
+	if err != nil {
+		return err
+	}
+	if err := secretSet("gitea-token", token); err != nil {
+		return err
+	}
+	fmt.Printf("Logged in to %s as %s; token stored in the keyring\n", giteaURL(), login)
+	return nil
+}
+
+// giteaWhoami validates a token by asking who owns it.
+func giteaWhoami(token string) (string, error) {
+	req, err := http.NewRequest("GET", giteaURL()+"/api/v1/user", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "token "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("gitea: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token rejected: HTTP %s", resp.Status)
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return "", err
+	}
+	return user.Login, nil
+}
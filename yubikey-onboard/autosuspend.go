@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+func init() {
+	checks = append(checks, check{"USB autosuspend", checkAutosuspend})
+	register(&command{
+		name:    "fix-autosuspend",
+		summary: "install a udev rule keeping the kernel from suspending the YubiKey",
+		run:     runFixAutosuspend,
+	})
+}
+
+// yubicoVendorID is Yubico's USB vendor ID as it appears in sysfs.
+const yubicoVendorID = "1050"
+
+// autosuspendRulePath is where the fix lives; the 70- prefix sorts it
+// after the distro defaults so it wins.
+const autosuspendRulePath = "/etc/udev/rules.d/70-yubikey-no-autosuspend.rules"
+
+const autosuspendRule = `# Installed by yubikey-onboard (fix-autosuspend).
+# Autosuspend powers the reader down mid-session and the card "vanishes";
+# keep Yubico devices awake.
+ACTION=="add", SUBSYSTEM=="usb", ATTR{idVendor}=="` + yubicoVendorID + `", ATTR{power/control}="on", ATTR{power/autosuspend}="-1"
+`
+
+// checkAutosuspend reports whether the kernel is allowed to power the
+// YubiKey down. Autosuspend is the classic cause of "my card disappears
+// after a few minutes" - the device is fine, the port went to sleep.
+func checkAutosuspend() error {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	devs, err := yubicoSysfsDevices()
+	if err != nil || len(devs) == 0 {
+		return nil // no card inserted; nothing to judge
+	}
+	for _, dev := range devs {
+		data, err := os.ReadFile(filepath.Join(dev, "power", "control"))
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(string(data)) == "auto" {
+			return fmt.Errorf("the kernel may suspend the YubiKey's USB port, which drops the card mid-session; run `%s fix-autosuspend`", brandProduct)
+		}
+	}
+	return nil
+}
+
+// yubicoSysfsDevices lists sysfs entries for connected Yubico devices.
+func yubicoSysfsDevices() ([]string, error) {
+	entries, err := filepath.Glob("/sys/bus/usb/devices/*")
+	if err != nil {
+		return nil, err
+	}
+	var devs []string
+	for _, dev := range entries {
+		data, err := os.ReadFile(filepath.Join(dev, "idVendor"))
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(string(data)) == yubicoVendorID {
+			devs = append(devs, dev)
+		}
+	}
+	return devs, nil
+}
+
+// runFixAutosuspend installs the udev rule pinning Yubico devices awake
+// and applies it to the device that is already plugged in.
+func runFixAutosuspend(args []string) error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("autosuspend tuning is Linux-only")
+	}
+	if data, err := os.ReadFile(autosuspendRulePath); err == nil && string(data) == autosuspendRule {
+		fmt.Println("The udev rule is already installed: " + autosuspendRulePath)
+		return nil
+	}
+	fmt.Println("This writes " + autosuspendRulePath + " so the kernel never powers")
+	fmt.Println("down Yubico USB devices. Remove the file to roll back.")
+	if !confirm("Install the udev rule?", true) {
+		return fmt.Errorf("declined")
+	}
+	tmp := filepath.Join(os.TempDir(), "yko-autosuspend.rules")
+	if err := writeFileAtomic(tmp, []byte(autosuspendRule), 0o644); err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	if err := runPrivileged("install udev autosuspend rule", "cp", tmp, autosuspendRulePath); err != nil {
+		return err
+	}
+	runPrivileged("reload udev rules", "udevadm", "control", "--reload-rules")
+	// The rule only fires on add; poke the devices that are already here.
+	for _, dev := range mustGlobYubico() {
+		runPrivileged("wake "+filepath.Base(dev), "sh", "-c",
+			"echo on > "+filepath.Join(dev, "power", "control"))
+	}
+	auditLog("fix-autosuspend: installed " + autosuspendRulePath + " (delete it to roll back)")
+	fmt.Printf("%s autosuspend disabled for Yubico devices\n", sym().ok)
+	return nil
+}
+
+func mustGlobYubico() []string {
+	devs, _ := yubicoSysfsDevices()
+	return devs
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+func init() {
+	register(&command{
+		name:    "bench",
+		summary: "measure signing latency with cold and cached PIN",
+		run:     runBench,
+	})
+}
+
+// benchSlowWarm is the warm-signature latency above which something is
+// wrong with the transport, not the card: a direct USB 2 connection
+// signs in well under half a second.
+const benchSlowWarm = time.Second
+
+// runBench times card signatures in two regimes: one cold signature
+// (PIN entry and any touch included) and a series of warm ones against
+// the agent's PIN cache. The numbers put "signing feels slow"
+// complaints on a scale - warm times in the seconds almost always mean
+// a flaky USB hub or autosuspend, not gpg.
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	runs := fs.Int("n", 5, "number of warm signing runs")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	id, err := primaryIdentity()
+	if err != nil {
+		return err
+	}
+	msg, err := os.CreateTemp("", "yko-bench-")
+	if err != nil {
+		return err
+	}
+	msg.WriteString("bench\n")
+	msg.Close()
+	defer os.Remove(msg.Name())
+	sig := msg.Name() + ".asc"
+	defer os.Remove(sig)
+
+	sign := func() (time.Duration, error) {
+		start := now()
+		_, err := output("gpg", "--batch", "--yes", "--armor", "--detach-sign",
+			"--local-user", id.fingerprint, "--output", sig, msg.Name())
+		return now().Sub(start), err
+	}
+
+	// Drop the agent's cached PIN so the first signature pays full price.
+	output("gpg-connect-agent", "reloadagent", "/bye")
+	fmt.Println("Cold signature (enter the PIN; touch the key if it blinks)...")
+	cold, err := sign()
+	if err != nil {
+		return fmt.Errorf("signing failed: %w", err)
+	}
+
+	warm := make([]time.Duration, 0, *runs)
+	fmt.Printf("Warm signatures (%d runs, PIN cached)...\n", *runs)
+	for i := 0; i < *runs; i++ {
+		d, err := sign()
+		if err != nil {
+			return fmt.Errorf("warm run %d failed: %w", i+1, err)
+		}
+		warm = append(warm, d)
+	}
+	sort.Slice(warm, func(i, j int) bool { return warm[i] < warm[j] })
+	median := warm[len(warm)/2]
+
+	fmt.Println()
+	fmt.Printf("  cold (PIN + touch):  %s\n", cold.Round(time.Millisecond))
+	fmt.Printf("  warm median:         %s\n", median.Round(time.Millisecond))
+	fmt.Printf("  warm min/max:        %s / %s\n",
+		warm[0].Round(time.Millisecond), warm[len(warm)-1].Round(time.Millisecond))
+	if median > benchSlowWarm {
+		fmt.Printf("  %s warm signatures should take well under a second; latency like this\n", sym().warn)
+		fmt.Println("  usually means a USB hub or autosuspend problem - try a direct port")
+	} else {
+		fmt.Printf("  %s warm latency looks healthy\n", sym().ok)
+	}
+	return nil
+}
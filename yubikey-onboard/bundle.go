@@ -0,0 +1,128 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// The key bundle is every employee's public key concatenated in armor,
+// baked into release binaries so a fresh laptop can onboard with zero
+// network. An admin refreshes it with `bundle update` before cutting a
+// release; the detached signature travels alongside it.
+//
+//go:embed keys.bundle
+var embeddedBundle []byte
+
+//go:embed keys.bundle.sig
+var embeddedBundleSig []byte
+
+func init() {
+	register(&command{
+		name:    "bundle",
+		summary: "update the embedded public-key bundle from the local keyring",
+		run:     runBundle,
+	})
+}
+
+// importEmployeeKey imports the named employee's key from the embedded
+// bundle. name matches the uid or email, case-insensitively.
+func importEmployeeKey(name string) error {
+	if len(embeddedBundle) == 0 {
+		return fmt.Errorf("this binary was built without a key bundle; run `bundle update` and rebuild, or onboard online")
+	}
+	if err := verifyBundle(); err != nil {
+		fmt.Println(sym().fail + " " + err.Error())
+		if !confirm("Import the unverified bundle anyway?", false) {
+			return fmt.Errorf("bundle rejected")
+		}
+	}
+
+	tmp, err := os.CreateTemp("", "yko-bundle")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	tmp.Write(embeddedBundle)
+	tmp.Close()
+
+	out, err := output("gpg", "--import", "--import-options", "show-only", "--with-colons", tmp.Name())
+	if err != nil {
+		return err
+	}
+	if !bundleHasEmployee(out, name) {
+		return fmt.Errorf("no key matching %q in the embedded bundle", name)
+	}
+	if out, err := output("gpg", "--import", tmp.Name()); err != nil {
+		return fmt.Errorf("import failed: %v: %s", err, out)
+	}
+	fmt.Printf("Imported the key bundle; %s's key is now in the keyring\n", name)
+	return nil
+}
+
+// bundleHasEmployee scans show-only colon output for a uid containing
+// the employee name or email.
+func bundleHasEmployee(colons, name string) bool {
+	needle := strings.ToLower(name)
+	for _, line := range strings.Split(colons, "\n") {
+		if strings.HasPrefix(line, "uid:") &&
+			strings.Contains(strings.ToLower(line), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyBundle checks the embedded detached signature. Verification
+// needs the signing admin's key in the keyring already, which a truly
+// offline first run will not have - the caller decides what to do then.
+func verifyBundle() error {
+	if len(embeddedBundleSig) == 0 {
+		return fmt.Errorf("bundle has no embedded signature")
+	}
+	data, err := os.CreateTemp("", "yko-bundle")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(data.Name())
+	data.Write(embeddedBundle)
+	data.Close()
+	sig, err := os.CreateTemp("", "yko-bundle-sig")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(sig.Name())
+	sig.Write(embeddedBundleSig)
+	sig.Close()
+	if out, err := exec.Command("gpg", "--verify", sig.Name(), data.Name()).CombinedOutput(); err != nil {
+		return fmt.Errorf("bundle signature verification failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// runBundle regenerates keys.bundle from every public key in the
+// admin's keyring and signs it with their card. Rebuild afterwards to
+// bake the new bundle in.
+func runBundle(args []string) error {
+	if len(args) != 1 || args[0] != "update" {
+		return fmt.Errorf("usage: bundle update")
+	}
+	armored, err := output("gpg", "--armor", "--export")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(armored) == "" {
+		return fmt.Errorf("the local keyring has no public keys to bundle")
+	}
+	if err := writeFileAtomic("keys.bundle", []byte(armored), 0o644); err != nil {
+		return err
+	}
+	os.Remove("keys.bundle.sig")
+	if _, err := output("gpg", "--armor", "--detach-sign", "--output", "keys.bundle.sig", "keys.bundle"); err != nil {
+		return fmt.Errorf("signing the bundle failed: %w", err)
+	}
+	fmt.Println("Wrote keys.bundle and keys.bundle.sig; rebuild to embed them")
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"yubikey-onboard/internal/parse"
+)
+
+func init() {
+	register(&command{
+		name:    "status",
+		summary: "show card identity: serial, cardholder, URL, PIN retries",
+		run:     runStatus,
+	})
+	registerStep(&step{
+		name:      "cardholder",
+		desc:      "Write cardholder name and key URL onto the card",
+		optional:  true,
+		exclusive: true,
+		after:     []string{"pin"},
+		explain: "The card stores a cardholder name and a URL to your public key. " +
+			"A labelled card finds its way home when left in a conference-room " +
+			"laptop, and the URL lets any machine fetch your key from the card alone.",
+		run: cardholderStep,
+	})
+}
+
+// cardholderData is the identification block of the OpenPGP applet.
+// A populated card can be returned to its owner; a blank one is
+// e-waste with a PIN.
+type cardholderData struct {
+	name     string
+	language string
+	url      string
+}
+
+// readCardholder parses the identification fields from gpg --card-status.
+func readCardholder() (cardholderData, error) {
+	out, err := output("gpg", "--card-status")
+	if err != nil {
+		return cardholderData{}, err
+	}
+	cs := parse.CardStatusOutput(out)
+	return cardholderData{name: cs.Holder, language: cs.Language, url: cs.URL}, nil
+}
+
+// cardEdit drives gpg --card-edit with scripted answers. gpg has no
+// non-interactive interface for the identification fields, so the
+// answers go in over --command-fd like a human typing.
+func cardEdit(answers ...string) error {
+	cmd := exec.Command("gpg", "--command-fd", "0", "--status-fd", "2",
+		"--pinentry-mode", "ask", "--card-edit")
+	cmd.Stdin = strings.NewReader(strings.Join(answers, "\n") + "\n")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("gpg --card-edit: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// setCardholderName writes the holder's name; gpg asks surname first.
+func setCardholderName(surname, given string) error {
+	return cardEdit("admin", "name", surname, given, "quit")
+}
+
+func setCardLanguage(lang string) error {
+	return cardEdit("admin", "lang", lang, "quit")
+}
+
+func setCardURL(url string) error {
+	return cardEdit("admin", "url", url, "quit")
+}
+
+// cardholderStep fills in the identification fields during
+// provisioning. The admin PIN is prompted by pinentry as usual.
+func cardholderStep(ctx *setupContext) error {
+	cur, err := readCardholder()
+	if err != nil {
+		return err
+	}
+	id, _ := ctx.identity()
+
+	defGiven, defSurname := splitName(uidName(id.uid))
+	given := promptString("Given name for the card?", defGiven)
+	surname := promptString("Surname for the card?", defSurname)
+	if given != "" || surname != "" {
+		if err := setCardholderName(surname, given); err != nil {
+			return err
+		}
+	}
+
+	lang := promptString("Language preference (two-letter code)?", firstNonEmpty(cur.language, "en"))
+	if lang != "" && lang != cur.language {
+		if err := setCardLanguage(lang); err != nil {
+			return err
+		}
+	}
+	if err := ensureCardURL(id); err != nil {
+		return err
+	}
+	fmt.Println("  cardholder data written; `status` shows the result")
+	return nil
+}
+
+// uidName strips the email part from a gpg uid like "Ada L <ada@x>".
+func uidName(uid string) string {
+	if i := strings.Index(uid, "<"); i > 0 {
+		return strings.TrimSpace(uid[:i])
+	}
+	return strings.TrimSpace(uid)
+}
+
+// splitName guesses given/surname from a display name: last word is
+// the surname, the rest the given name. Only a prompt default.
+func splitName(name string) (given, surname string) {
+	parts := strings.Fields(name)
+	if len(parts) == 0 {
+		return "", ""
+	}
+	return strings.Join(parts[:len(parts)-1], " "), parts[len(parts)-1]
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func runStatus(args []string) error {
+	serial, err := cardSerial()
+	if err != nil {
+		return err
+	}
+	d, err := readCardholder()
+	if err != nil {
+		return err
+	}
+	fmt.Println("Serial:     " + serial)
+	fmt.Println("Cardholder: " + orNotSet(d.name))
+	fmt.Println("Language:   " + orNotSet(d.language))
+	fmt.Println("Key URL:    " + orNotSet(d.url))
+	if user, reset, admin, err := pinRetryCounters(); err == nil {
+		fmt.Printf("PIN tries:  %d user / %d reset / %d admin\n", user, reset, admin)
+	}
+	return nil
+}
+
+func orNotSet(v string) string {
+	if v == "" {
+		return "(not set)"
+	}
+	return v
+}
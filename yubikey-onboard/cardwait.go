@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Hardware-wait behavior. The interactive default - ask the user to
+// insert the key and press Enter - blocks pipelines, so unattended runs
+// pick one of the flags instead.
+var (
+	// waitForCard polls for the card for this long instead of prompting.
+	waitForCard time.Duration
+	// noCardWait skips the wait entirely, for flows that legitimately
+	// run without the hardware (printing sudo scripts, answers capture).
+	noCardWait bool
+)
+
+// cardPollInterval is how often the polling mode re-asks the reader.
+const cardPollInterval = 2 * time.Second
+
+// ensureCardPresent blocks until a card answers, in whichever way the
+// flags ask for. It returns nil immediately when a card is already
+// there.
+func ensureCardPresent() error {
+	if noCardWait || dryRun {
+		return nil
+	}
+	if _, err := cardSerial(); err == nil {
+		return nil
+	}
+	if waitForCard > 0 {
+		fmt.Printf("No card detected; polling for up to %s...\n", waitForCard)
+		deadline := now().Add(waitForCard)
+		for now().Before(deadline) {
+			time.Sleep(cardPollInterval)
+			if serial, err := cardSerial(); err == nil {
+				fmt.Printf("%s card %s detected\n", sym().ok, serial)
+				return nil
+			}
+		}
+		return fmt.Errorf("no card appeared within %s (use -no-card-wait to run without one)", waitForCard)
+	}
+	for {
+		answer := promptString("Insert your YubiKey and press Enter (q gives up):", "")
+		if strings.EqualFold(strings.TrimSpace(answer), "q") {
+			return fmt.Errorf("no card inserted")
+		}
+		serial, err := cardSerial()
+		if err == nil {
+			fmt.Printf("%s card %s detected\n", sym().ok, serial)
+			return nil
+		}
+		fmt.Printf("  %s still no card: %v\n", sym().warn, err)
+		if nonInteractive {
+			// The prompt resolves instantly in unattended mode; looping
+			// would spin forever.
+			return fmt.Errorf("no card detected (use -wait-for-card to poll for one)")
+		}
+	}
+}
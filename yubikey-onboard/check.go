@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func init() {
+	register(&command{
+		name:    "check",
+		summary: "verify the machine is correctly set up for card-backed signing",
+		run:     runCheck,
+	})
+}
+
+// check is one verification. Checks must be read-only.
+type check struct {
+	name string
+	run  func() error
+}
+
+var checks = []check{
+	{"gpg installed", checkGPGInstalled},
+	{"card present", checkCardPresent},
+	{"agent serves ssh key", checkAgentKey},
+	{"git signing configured", checkGitSigning},
+	{"PIN retries healthy", checkPINRetries},
+	{"pinentry present", checkPinentry},
+	{"config files owned by you", checkOwnership},
+}
+
+func runCheck(args []string) error {
+	st, err := loadState()
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 && args[0] == "--history" {
+		return printCheckHistory(st)
+	}
+	if len(args) >= 2 && args[0] == "--output" && args[1] == "json" {
+		outputFormat = "json" // older spelling of -format json
+	}
+	r, err := newRenderer()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range checks {
+		err := c.run()
+		recordCheckResult(st, c.name, err)
+		if err != nil {
+			if since := failingSince(st, c.name); since != "" {
+				err = fmt.Errorf("%v (failing since %s)", err, since)
+			}
+		}
+		r.result(c.name, err)
+	}
+	if pending := pendingTodos(st); len(pending) > 0 {
+		r.info("\nStill on your plate:")
+		for _, p := range pending {
+			r.info("  " + sym().bullet + " " + p)
+		}
+		r.info("mark items done with: yubikey-onboard todo done <item>")
+	}
+
+	if err := saveState(st); err != nil {
+		return err
+	}
+	if failed := r.close(); failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// recordCheckResult folds one result into the history, resetting the
+// Since timestamp only when the result flips.
+func recordCheckResult(st *state, name string, err error) {
+	if st.CheckHistory == nil {
+		st.CheckHistory = map[string]checkRecord{}
+	}
+	rec, seen := st.CheckHistory[name]
+	ok := err == nil
+	if !seen || rec.OK != ok {
+		rec.Since = now()
+	}
+	rec.OK = ok
+	rec.LastRun = now()
+	if err != nil {
+		rec.LastFail = err.Error()
+	}
+	st.CheckHistory[name] = rec
+}
+
+// failingSince returns a human date when the check has been failing
+// across more than one run, and "" for a fresh failure.
+func failingSince(st *state, name string) string {
+	rec, ok := st.CheckHistory[name]
+	if !ok || rec.OK || rec.Since.IsZero() || now().Sub(rec.Since) < time.Hour {
+		return ""
+	}
+	return rec.Since.Format("Jan 2")
+}
+
+func printCheckHistory(st *state) error {
+	if len(st.CheckHistory) == 0 {
+		return fmt.Errorf("no recorded runs yet; run `check` first")
+	}
+	// Walk the registered order so the listing matches a normal run.
+	for _, c := range checks {
+		rec, ok := st.CheckHistory[c.name]
+		if !ok {
+			continue
+		}
+		state := sym().ok + " passing"
+		if !rec.OK {
+			state = sym().fail + " failing"
+		}
+		fmt.Printf("%s %s since %s (last run %s)\n",
+			state, c.name, rec.Since.Format("Jan 2 15:04"), rec.LastRun.Format("Jan 2 15:04"))
+		if !rec.OK && rec.LastFail != "" {
+			fmt.Println("    " + rec.LastFail)
+		}
+	}
+	return nil
+}
+
+func checkGPGInstalled() error {
+	if !haveExec("gpg") {
+		return fmt.Errorf("gpg not found in PATH")
+	}
+	return nil
+}
+
+// checkPinentry verifies some pinentry exists, since without one every
+// PIN prompt dies with "Inappropriate ioctl for device".
+func checkPinentry() error {
+	for _, name := range []string{"pinentry", "pinentry-curses", "pinentry-gnome3", "pinentry-qt", "pinentry-mac", "pinentry-tty"} {
+		if haveExec(name) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no pinentry program found; gpg cannot ask for the PIN")
+}
+
+func checkCardPresent() error {
+	out, err := output("gpg", "--card-status")
+	if err != nil {
+		return fmt.Errorf("no card detected: %w", err)
+	}
+	if !strings.Contains(out, "Serial number") {
+		return fmt.Errorf("gpg --card-status did not report a serial number")
+	}
+	return nil
+}
+
+func checkAgentKey() error {
+	keys, err := agentKeys()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("ssh-add -L lists no keys")
+	}
+	return nil
+}
+
+func checkPINRetries() error {
+	user, _, admin, err := pinRetryCounters()
+	if err != nil {
+		return err
+	}
+	if user <= 1 || admin <= 1 {
+		return fmt.Errorf("low retry count (user %d, admin %d); sort this out before the card locks", user, admin)
+	}
+	return nil
+}
+
+func checkGitSigning() error {
+	key, err := output("git", "config", "--get", "user.signingkey")
+	if err != nil || key == "" {
+		return fmt.Errorf("user.signingkey is not set")
+	}
+	sign, _ := output("git", "config", "--get", "commit.gpgsign")
+	if sign != "true" {
+		return fmt.Errorf("commit.gpgsign is not true")
+	}
+	// Drift: after a key rotation git keeps signing with the old key
+	// until someone notices the forge rejecting pushes.
+	if id, err := primaryIdentity(); err == nil && !fingerprintMatches(key, id.fingerprint) {
+		return fmt.Errorf("user.signingkey (%s) does not match the active key %s; rerun setup or fix git config", key, id.fingerprint)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"yubikey-onboard/internal/confedit"
+)
+
+func init() {
+	register(&command{
+		name:    "sign-service",
+		summary: "serve a local health endpoint for CI signing machines",
+		run:     runSignService,
+	})
+}
+
+// runCIProfile provisions an unattended build machine that signs
+// artifacts with a permanently attached YubiKey. No prompts: the PIN
+// comes from YKO_CI_PIN or Vault, pinentry runs in loopback mode, and
+// sign-service is installed as a hardened systemd unit.
+func runCIProfile() error {
+	nonInteractive = true
+	fmt.Println("Provisioning CI signing profile")
+
+	pin, err := ciPIN()
+	if err != nil {
+		return err
+	}
+	redact.note(pin)
+
+	if err := configureLoopbackPinentry(); err != nil {
+		return err
+	}
+
+	// Prove the PIN works before installing anything that depends on it.
+	if _, err := output("gpg", "--card-status"); err != nil {
+		return fmt.Errorf("no card visible on this machine: %w", err)
+	}
+	if err := loopbackSignProbe(pin); err != nil {
+		return fmt.Errorf("loopback signing probe failed: %w", err)
+	}
+	fmt.Println(sym().ok + " loopback signing works")
+
+	if err := installSignServiceUnit(); err != nil {
+		return err
+	}
+	fmt.Println("CI profile complete; sign-service health endpoint on " + signServiceAddr)
+	return nil
+}
+
+// ciPIN resolves the card PIN without prompting: environment first,
+// then the org's Vault mount.
+func ciPIN() (string, error) {
+	if pin := os.Getenv("YKO_CI_PIN"); pin != "" {
+		return pin, nil
+	}
+	c, err := vaultLogin()
+	if err != nil {
+		return "", fmt.Errorf("no YKO_CI_PIN in the environment and Vault unavailable: %w", err)
+	}
+	kv, err := c.readKV("ci-signing/" + hostnameOr("default"))
+	if err != nil {
+		return "", err
+	}
+	if pin := kv["pin"]; pin != "" {
+		return pin, nil
+	}
+	return "", fmt.Errorf("Vault entry has no pin field")
+}
+
+func hostnameOr(def string) string {
+	h, err := os.Hostname()
+	if err != nil || h == "" {
+		return def
+	}
+	return h
+}
+
+// configureLoopbackPinentry lets gpg take the PIN over the command
+// channel instead of a pinentry dialog, which unattended machines
+// cannot answer.
+func configureLoopbackPinentry() error {
+	gnupg := gpgDirs().Home
+	if err := os.MkdirAll(gnupg, 0o700); err != nil {
+		return err
+	}
+	if err := appendConfigLine(filepath.Join(gnupg, "gpg-agent.conf"), "allow-loopback-pinentry"); err != nil {
+		return err
+	}
+	if err := appendConfigLine(filepath.Join(gnupg, "gpg.conf"), "pinentry-mode loopback"); err != nil {
+		return err
+	}
+	output("gpgconf", "--kill", "gpg-agent")
+	return nil
+}
+
+// appendConfigLine sets the directive in path, updating an existing
+// entry for the same key in place rather than appending a duplicate -
+// gpg honors whichever it reads first, so a stale earlier line would
+// win forever. Comments and the user's own lines are left untouched.
+func appendConfigLine(path, line string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	key, value, _ := strings.Cut(line, " ")
+	updated := confedit.Set(data, key, value)
+	if string(updated) == string(data) {
+		return nil
+	}
+	return writeFileAtomic(path, updated, 0o600)
+}
+
+// loopbackSignProbe makes a throwaway signature to confirm the PIN and
+// loopback configuration before anything depends on them.
+func loopbackSignProbe(pin string) error {
+	id, err := primaryIdentity()
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp("", "yko-sign-probe")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer os.Remove(f.Name() + ".asc")
+	f.WriteString("probe\n")
+	f.Close()
+	_, err = output("gpg", "--batch", "--pinentry-mode", "loopback",
+		"--passphrase", pin, "--local-user", id.fingerprint,
+		"--armor", "--detach-sign", f.Name())
+	return err
+}
+
+// installSignServiceUnit writes a hardened systemd unit running
+// sign-service under the invoking user.
+func installSignServiceUnit() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	unit := fmt.Sprintf(`[Unit]
+Description=YubiKey CI signing health service
+After=network.target
+
+[Service]
+ExecStart=%s sign-service
+Restart=on-failure
+# Hardening: the service only needs the agent socket and the USB card.
+NoNewPrivileges=yes
+ProtectSystem=strict
+ProtectHome=read-only
+PrivateTmp=yes
+RestrictAddressFamilies=AF_UNIX AF_INET AF_INET6
+MemoryDenyWriteExecute=yes
+
+[Install]
+WantedBy=multi-user.target
+`, exe)
+	path := "/etc/systemd/system/yubikey-sign-service.service"
+	tmp := filepath.Join(os.TempDir(), "yubikey-sign-service.service")
+	if err := writeFileAtomic(tmp, []byte(unit), 0o644); err != nil {
+		return err
+	}
+	if err := runPrivileged("install sign-service unit", "cp", tmp, path); err != nil {
+		return err
+	}
+	if err := runPrivileged("reload systemd", "systemctl", "daemon-reload"); err != nil {
+		return err
+	}
+	return runPrivileged("enable sign-service", "systemctl", "enable", "--now", "yubikey-sign-service")
+}
+
+const signServiceAddr = "127.0.0.1:9911"
+
+// runSignService serves /healthz for monitoring: 200 when the card is
+// present and the agent serves its key, 503 otherwise.
+func runSignService(args []string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := output("gpg", "--card-status"); err != nil {
+			http.Error(w, "card not present: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if _, err := output("ssh-add", "-L"); err != nil {
+			http.Error(w, "agent serves no keys: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	ln, err := net.Listen("tcp", signServiceAddr)
+	if err != nil {
+		return err
+	}
+	fmt.Println("sign-service listening on " + signServiceAddr)
+	return http.Serve(ln, mux)
+}
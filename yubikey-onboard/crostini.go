@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func init() {
+	checks = append(checks, check{"Crostini quirks", checkCrostini})
+}
+
+// isCrostini detects the ChromeOS Linux container.
+func isCrostini() bool {
+	if _, err := os.Stat("/dev/.cros_milestone"); err == nil {
+		return true
+	}
+	if data, err := os.ReadFile("/etc/systemd/user/sommelier@0.service.d/cros-sommelier-override.conf"); err == nil && len(data) > 0 {
+		return true
+	}
+	host, _ := os.Hostname()
+	return host == "penguin"
+}
+
+// checkCrostini verifies the container-specific plumbing a YubiKey
+// needs under ChromeOS. A no-op elsewhere.
+func checkCrostini() error {
+	if !isCrostini() {
+		return nil
+	}
+	// USB passthrough must be enabled per-device in ChromeOS settings;
+	// without it the container never sees the key.
+	if out, err := output("lsusb"); err != nil || !strings.Contains(out, "Yubico") {
+		return fmt.Errorf("the container does not see the YubiKey; enable it under ChromeOS Settings > Linux > USB preferences, then replug")
+	}
+	// Some Crostini images ship without a systemd user instance, which
+	// breaks the usual socket activation for gpg-agent.
+	if _, err := output("systemctl", "--user", "is-system-running"); err != nil {
+		fmt.Println("  note: no systemd user instance; start gpg-agent from your shell rc instead (gpgconf --launch gpg-agent)")
+	}
+	// pcscd fights scdaemon over the reader in the container; direct
+	// CCID access works better here.
+	if _, err := output("pgrep", "pcscd"); err == nil {
+		return fmt.Errorf("pcscd conflicts with scdaemon in Crostini; disable it (sudo systemctl disable --now pcscd) and let gpg talk CCID directly")
+	}
+	return nil
+}
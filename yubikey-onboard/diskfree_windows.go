@@ -0,0 +1,11 @@
+//go:build windows
+
+package main
+
+import "errors"
+
+// freeDiskBytes is not implemented on Windows without cgo or x/sys;
+// the preflight check skips rather than guesses.
+func freeDiskBytes(path string) (uint64, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+func init() {
+	register(&command{
+		name:    "doctor",
+		summary: "run the checks and, with --fix, repair what they find",
+		run:     runDoctor,
+	})
+}
+
+// fixes maps a check name to its remediation. Checks themselves stay
+// read-only; doctor is the only caller allowed to change anything, and
+// only after the matching check has actually failed.
+var fixes = map[string]func() error{
+	"card present":              fixRestartAgent,
+	"agent serves ssh key":      fixAgentEnvironment,
+	"config files owned by you": fixGnupgOwnership,
+	"pinentry present":          fixPinentry,
+}
+
+// runDoctor reruns the standard checks and pairs each failure with its
+// remediation. Without --fix it only says what it would do; with --fix
+// it applies the remediation and immediately reruns the check, so the
+// user sees repaired/still-broken rather than a wall of advice.
+func runDoctor(args []string) error {
+	apply := false
+	for _, a := range args {
+		switch a {
+		case "--fix", "-fix":
+			apply = true
+		default:
+			return fmt.Errorf("usage: doctor [--fix]")
+		}
+	}
+
+	broken := 0
+	for _, c := range checks {
+		err := c.run()
+		if err == nil {
+			fmt.Printf("%s %s\n", sym().ok, c.name)
+			continue
+		}
+		fmt.Printf("%s %s: %v\n", sym().fail, c.name, err)
+		fix, ok := fixes[c.name]
+		if !ok {
+			broken++
+			continue
+		}
+		if !apply {
+			fmt.Println("    doctor can try to fix this; rerun with --fix")
+			broken++
+			continue
+		}
+		if dryRun {
+			fmt.Println("    dry-run: would apply the fix for this")
+			broken++
+			continue
+		}
+		if ferr := fix(); ferr != nil {
+			fmt.Printf("    fix failed: %v\n", ferr)
+			broken++
+			continue
+		}
+		if err := c.run(); err != nil {
+			fmt.Printf("    still failing after the fix: %v\n", err)
+			broken++
+			continue
+		}
+		fmt.Printf("    %s fixed\n", sym().ok)
+		auditLog("doctor fixed: " + c.name)
+	}
+	if broken > 0 {
+		return fmt.Errorf("%d problem(s) remain; `setup` rebuilds everything from scratch", broken)
+	}
+	return nil
+}
+
+// fixRestartAgent bounces gpg-agent (and with it scdaemon). The agent
+// holding a stale card handle is the most common "no card detected"
+// cause after replugging the key.
+func fixRestartAgent() error {
+	output("gpgconf", "--kill", "gpg-agent")
+	if _, err := output("gpgconf", "--launch", "gpg-agent"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// fixAgentEnvironment restarts the agent and re-points the session's
+// SSH_AUTH_SOCK at it, the same wiring the gui-env step installs.
+func fixAgentEnvironment() error {
+	if err := fixRestartAgent(); err != nil {
+		return err
+	}
+	sock := gpgDirs().AgentSSHSocket
+	output("systemctl", "--user", "set-environment", "SSH_AUTH_SOCK="+sock)
+	if os.Getenv("SSH_AUTH_SOCK") != sock {
+		fmt.Println("    run in this shell: export SSH_AUTH_SOCK=" + sock)
+	}
+	return nil
+}
+
+// fixGnupgOwnership hands the gpg home back to the user and restores the
+// permissions gpg insists on.
+func fixGnupgOwnership() error {
+	home := gpgDirs().Home
+	if err := runPrivileged("restore ownership of "+home, "chown", "-R", os.Getenv("USER"), home); err != nil {
+		return err
+	}
+	return os.Chmod(home, 0o700)
+}
+
+// fixPinentry re-points the pinentry alternative at the distro default,
+// undoing a half-removed pinentry package.
+func fixPinentry() error {
+	if runtime.GOOS != "linux" || !haveExec("update-alternatives") {
+		return fmt.Errorf("no alternatives system here; install a pinentry package by hand")
+	}
+	if err := runPrivileged("reset pinentry alternative", "update-alternatives", "--auto", "pinentry"); err != nil {
+		return err
+	}
+	return fixRestartAgent()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+func init() {
+	registerStep(&step{
+		name:      "email",
+		desc:      "Configure the mail client for signed email",
+		optional:  true,
+		exclusive: true,
+		run:       emailStep,
+	})
+}
+
+func emailStep(ctx *setupContext) error {
+	if skipPersistent("mail client configuration") {
+		return nil
+	}
+	id, err := ctx.identity()
+	if err != nil {
+		return err
+	}
+	client := promptString("Which client do you use (thunderbird/mutt/none)?", "thunderbird")
+	switch client {
+	case "thunderbird":
+		return configureThunderbird(id)
+	case "mutt", "neomutt":
+		return configureMutt(id)
+	case "none", "":
+		return nil
+	default:
+		return fmt.Errorf("unknown mail client %q", client)
+	}
+}
+
+// configureThunderbird enables external GnuPG support and records the
+// signing key ID in each profile's user.js.
+func configureThunderbird(id gpgIdentity) error {
+	root := thunderbirdRoot()
+	profiles, err := filepath.Glob(filepath.Join(root, "*.default*"))
+	if err != nil || len(profiles) == 0 {
+		return fmt.Errorf("no Thunderbird profile found under %s", root)
+	}
+	keyID := id.fingerprint[len(id.fingerprint)-16:]
+	prefs := fmt.Sprintf(
+		"// added by yubikey-onboard\n"+
+			"user_pref(\"mail.openpgp.allow_external_gnupg\", true);\n"+
+			"user_pref(\"mail.identity.default.last_entered_external_gnupg_key_id\", \"%s\");\n",
+		keyID)
+	for _, p := range profiles {
+		userJS := filepath.Join(p, "user.js")
+		existing, _ := os.ReadFile(userJS)
+		if strings.Contains(string(existing), "allow_external_gnupg") {
+			fmt.Printf("  %s already configured\n", userJS)
+			continue
+		}
+		f, err := os.OpenFile(userJS, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		if _, err := f.WriteString(prefs); err != nil {
+			f.Close()
+			return err
+		}
+		f.Close()
+		fmt.Printf("  wrote %s\n", userJS)
+	}
+	fmt.Println("  restart Thunderbird, then pick the external key under Account Settings > End-to-End Encryption")
+	return nil
+}
+
+func thunderbirdRoot() string {
+	home, _ := os.UserHomeDir()
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(home, "Library", "Thunderbird", "Profiles")
+	}
+	return filepath.Join(home, ".thunderbird")
+}
+
+// configureMutt writes a gpg.rc the user sources from their muttrc.
+func configureMutt(id gpgIdentity) error {
+	home, _ := os.UserHomeDir()
+	path := filepath.Join(home, ".config", "mutt", "gpg.rc")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	content := fmt.Sprintf(
+		"# generated by yubikey-onboard\n"+
+			"set crypt_use_gpgme = yes\n"+
+			"set pgp_default_key = %s\n"+
+			"set crypt_autosign = yes\n"+
+			"set crypt_replysign = yes\n"+
+			"set crypt_replysignencrypted = yes\n",
+		id.fingerprint)
+	if err := writeFileAtomic(path, []byte(content), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("  wrote %s\n  add `source %s` to your muttrc\n", path, path)
+	return nil
+}
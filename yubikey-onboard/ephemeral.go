@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// ephemeralMode (-ephemeral) runs everything against a throwaway
+// GNUPGHOME and skips persistent changes to the machine - rc files,
+// desktop config, hooks. Meant for admins provisioning keys from a
+// Tails stick or other live OS.
+var ephemeralMode bool
+
+// enterEphemeralMode points gpg at a scratch home for this run.
+func enterEphemeralMode() error {
+	dir, err := os.MkdirTemp("", "yko-gnupghome-")
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(dir, 0o700); err != nil {
+		return err
+	}
+	os.Setenv("GNUPGHOME", dir)
+	fmt.Println("ephemeral mode: GNUPGHOME=" + dir + " (left for you to shred)")
+	return nil
+}
+
+// skipPersistent reports (and logs) when a step must not touch the
+// machine's durable configuration.
+func skipPersistent(what string) bool {
+	if !ephemeralMode {
+		return false
+	}
+	fmt.Println("  skipped " + what + " (ephemeral mode)")
+	return true
+}
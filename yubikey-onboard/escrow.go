@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// escrowBackend stores recovery material (admin PINs, reset codes)
+// somewhere the team can get it back from.
+type escrowBackend interface {
+	name() string
+	store(path, secret string) error
+}
+
+// escrowFor returns the backend selected in the org config.
+func escrowFor() (escrowBackend, error) {
+	switch org().PINEscrowBackend {
+	case "pass":
+		return passEscrow{}, nil
+	case "vault":
+		return vaultEscrow{}, nil
+	case "":
+		return nil, fmt.Errorf("no pin_escrow_backend configured in org.json")
+	default:
+		return nil, fmt.Errorf("unknown pin_escrow_backend %q", org().PINEscrowBackend)
+	}
+}
+
+// escrowAdminPIN offers to store the admin PIN for an admin-run
+// provisioning, and records that it happened.
+func escrowAdminPIN(id gpgIdentity, adminPIN string) error {
+	backend, err := escrowFor()
+	if err != nil {
+		fmt.Println("  " + err.Error() + "; skipping escrow")
+		return nil
+	}
+	if !confirm(fmt.Sprintf("Escrow the admin PIN to %s?", backend.name()), true) {
+		return nil
+	}
+	path := "yubikey/" + strings.ToLower(id.fingerprint) + "/admin-pin"
+	if err := backend.store(path, adminPIN); err != nil {
+		return err
+	}
+	fmt.Printf("  admin PIN escrowed to %s at %s\n", backend.name(), path)
+	return auditLog(fmt.Sprintf("escrowed admin PIN for %s to %s:%s", id.fingerprint, backend.name(), path))
+}
+
+// passEscrow stores secrets in the team pass repository.
+type passEscrow struct{}
+
+func (passEscrow) name() string { return "pass" }
+
+func (passEscrow) store(path, secret string) error {
+	cmd := exec.Command("pass", "insert", "-m", "-f", path)
+	cmd.Stdin = strings.NewReader(secret + "\n")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("pass insert: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// vaultEscrow stores secrets via the Vault HTTP API.
+type vaultEscrow struct{}
+
+func (vaultEscrow) name() string { return "vault" }
+
+func (vaultEscrow) store(path, secret string) error {
+	c, err := vaultLogin()
+	if err != nil {
+		return err
+	}
+	return c.writeKV(path, map[string]string{"value": secret})
+}
+
+// auditLog appends a timestamped line to the local audit trail.
+func auditLog(entry string) error {
+	if dryRun {
+		return nil // a preview leaves no trail
+	}
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	logPath := filepath.Join(filepath.Dir(path), "audit.log")
+	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s %s\n", now().Format("2006-01-02T15:04:05Z07:00"), entry)
+	return err
+}
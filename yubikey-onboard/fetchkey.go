@@ -0,0 +1,97 @@
+package main
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+func init() {
+	register(&command{
+		name:    "fetch-key",
+		summary: "import the public key from the URL stored on the card",
+		run:     runFetchKey,
+	})
+}
+
+// wkdURL builds the advanced-method WKD URL for an email address, the
+// counterpart of the tree writeWKDTree lays out.
+func wkdURL(email string) (string, error) {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return "", fmt.Errorf("malformed email %q", email)
+	}
+	hash := zbase32(sha1.Sum([]byte(strings.ToLower(local))))
+	return fmt.Sprintf("https://openpgpkey.%s/.well-known/openpgpkey/%s/hu/%s?l=%s",
+		strings.ToLower(domain), strings.ToLower(domain), hash, local), nil
+}
+
+// ensureCardURL writes the user's WKD URL into the card's URL slot if
+// it is still empty, so a brand-new laptop can fetch the public key
+// from the card alone.
+func ensureCardURL(id gpgIdentity) error {
+	cur, err := readCardholder()
+	if err != nil {
+		return err
+	}
+	if cur.url != "" {
+		return nil
+	}
+	url, err := wkdURL(id.email)
+	if err != nil {
+		return err
+	}
+	if err := setCardURL(url); err != nil {
+		return err
+	}
+	fmt.Println("  card key URL set to " + url)
+	return nil
+}
+
+// runFetchKey pulls the public key named by the card's URL field and
+// imports it. This is the whole new-laptop bootstrap: plug the card in,
+// run fetch-key, and git signing works without copying .key files.
+func runFetchKey(args []string) error {
+	d, err := readCardholder()
+	if err != nil {
+		return err
+	}
+	if d.url == "" {
+		return fmt.Errorf("the card has no public-key URL; run the cardholder step on a provisioned machine first")
+	}
+	fmt.Println("Fetching " + d.url)
+	resp, err := http.Get(d.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch %s: HTTP %s", d.url, resp.Status)
+	}
+	key, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "yko-fetch-key")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	tmp.Write(key)
+	tmp.Close()
+	if out, err := output("gpg", "--import", tmp.Name()); err != nil {
+		return fmt.Errorf("import failed: %v: %s", err, out)
+	}
+
+	// Let gpg associate the imported key with the card, creating the
+	// private-key stubs that make the agent serve it.
+	if _, err := output("gpg", "--card-status"); err != nil {
+		return err
+	}
+	fmt.Println("Key imported and linked to the card; run `check` to verify the rest")
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func init() {
+	registerStep(&step{
+		name:      "final-instructions",
+		desc:      "Show what to do next",
+		optional:  false,
+		exclusive: true,
+		after:     []string{"sign-test", "ssh-test", "disk-encryption"},
+		run:       finalInstructionsStep,
+	})
+}
+
+// finalInstructionsStep writes the full armored public key to a file
+// the user can paste from, instead of flooding (or truncating) the
+// terminal with it.
+func finalInstructionsStep(ctx *setupContext) error {
+	id, err := ctx.identity()
+	if err != nil {
+		return err
+	}
+	armored, err := exportPublicKey(id.fingerprint)
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	keyFile := filepath.Join(home, "gpg-public-key.asc")
+	if p := userCfg().KeyExportPath; p != "" {
+		if keyFile, err = resolveOutputPath(p); err != nil {
+			return err
+		}
+	}
+	if ephemeralMode {
+		keyFile = filepath.Join(os.Getenv("GNUPGHOME"), "gpg-public-key.asc")
+	}
+	if err := writeFileAtomic(keyFile, []byte(armored+"\n"), 0o644); err != nil {
+		return err
+	}
+
+	fmt.Println("  Your full GPG public key is in: " + keyFile)
+	fmt.Println("  Display it any time with: " + brandProduct + " show-keys")
+	fmt.Printf("  Upload it at %s/user/settings/keys, then run: %s gitea-verify\n", giteaURL(), brandProduct)
+	fmt.Println("  Track the remaining manual items with: " + brandProduct + " todo")
+	return nil
+}
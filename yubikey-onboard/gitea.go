@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func init() {
+	register(&command{
+		name:    "gitea-verify",
+		summary: "confirm the SSH and GPG keys are registered in Gitea",
+		run:     runGiteaVerify,
+	})
+}
+
+func giteaURL() string {
+	return org().GiteaURL
+}
+
+func giteaToken() (string, error) {
+	for _, env := range []string{"YKO_GITEA_TOKEN", "GITEA_TOKEN"} {
+		if t := os.Getenv(env); t != "" {
+			return t, nil
+		}
+	}
+	if t, err := secretGet("gitea-token"); err == nil && t != "" {
+		return t, nil
+	}
+	return "", fmt.Errorf("no Gitea API token; run `%s auth login gitea`", brandProduct)
+}
+
+func giteaGet(path string, dst interface{}) error {
+	token, err := giteaToken()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("GET", giteaURL()+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("gitea: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gitea: GET %s: HTTP %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
+
+func runGiteaVerify(args []string) error {
+	id, err := primaryIdentity()
+	if err != nil {
+		return err
+	}
+	st, err := loadState()
+	if err != nil {
+		return err
+	}
+
+	sshOK, err := giteaHasSSHKey()
+	if err != nil {
+		return err
+	}
+	report(st, "gitea-ssh-key", sshOK, "SSH key registered in Gitea")
+
+	gpgOK, err := giteaHasGPGKey(id.fingerprint)
+	if err != nil {
+		return err
+	}
+	report(st, "gitea-gpg-key", gpgOK, "GPG key registered and verified in Gitea")
+
+	if err := saveState(st); err != nil {
+		return err
+	}
+	if !sshOK || !gpgOK {
+		return fmt.Errorf("upload the missing key(s) at %s/user/settings/keys", giteaURL())
+	}
+	return sshAuthTest("git@" + hostFromURL(giteaURL()))
+}
+
+func report(st *state, todoKey string, ok bool, desc string) {
+	if ok {
+		fmt.Println("ok   " + desc)
+		if st.Todos == nil {
+			st.Todos = map[string]todoItem{}
+		}
+		st.Todos[todoKey] = todoItem{Done: true, DoneAt: now()}
+	} else {
+		fmt.Println("MISSING " + desc)
+	}
+}
+
+// giteaHasSSHKey compares the agent's key blobs with the keys on the
+// user's Gitea account.
+func giteaHasSSHKey() (bool, error) {
+	local, err := agentKeys()
+	if err != nil {
+		return false, err
+	}
+	var remote []struct {
+		Key string `json:"key"`
+	}
+	if err := giteaGet("/api/v1/user/keys", &remote); err != nil {
+		return false, err
+	}
+	for _, r := range remote {
+		rk, err := parseSSHPublicKey(strings.TrimSpace(r.Key))
+		if err != nil {
+			continue
+		}
+		for _, l := range local {
+			if base64.StdEncoding.EncodeToString(l.blob) == base64.StdEncoding.EncodeToString(rk.blob) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func giteaHasGPGKey(fingerprint string) (bool, error) {
+	var remote []struct {
+		KeyID    string `json:"key_id"`
+		Verified bool   `json:"verified"`
+	}
+	if err := giteaGet("/api/v1/user/gpg_keys", &remote); err != nil {
+		return false, err
+	}
+	for _, r := range remote {
+		if strings.HasSuffix(fingerprint, r.KeyID) {
+			return r.Verified, nil
+		}
+	}
+	return false, nil
+}
+
+// sshAuthTest proves the agent key is accepted by target without running
+// a command. Forges answer `ssh -T` with a greeting and a non-zero exit,
+// so the greeting counts as success too.
+func sshAuthTest(target string) error {
+	out, err := exec.Command("ssh", "-o", "BatchMode=yes", "-T", target).CombinedOutput()
+	text := strings.TrimSpace(string(out))
+	if err == nil || strings.Contains(text, "successfully authenticated") {
+		fmt.Println("ok   ssh authentication to " + target)
+		return nil
+	}
+	return fmt.Errorf("ssh to %s failed: %s", target, text)
+}
+
+func hostFromURL(u string) string {
+	u = strings.TrimPrefix(u, "https://")
+	u = strings.TrimPrefix(u, "http://")
+	host, _, _ := strings.Cut(u, "/")
+	return host
+}
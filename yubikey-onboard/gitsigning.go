@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	registerStep(&step{
+		name:     "git-signing",
+		desc:     "Point git at the card's signing key",
+		optional: false,
+		after:    []string{"pin"},
+		explain: "git needs to know which key signs your commits. This step sets " +
+			"user.signingkey and commit.gpgsign globally - and if they already " +
+			"point somewhere else it shows the difference instead of silently " +
+			"overwriting.",
+		run: gitSigningStep,
+	})
+}
+
+// forceGitConfig overwrites differing git signing config without
+// asking; set by setup -force.
+var forceGitConfig bool
+
+// desired global git configuration for card-backed signing.
+func gitSigningValues(fingerprint string) map[string]string {
+	return map[string]string{
+		"user.signingkey": fingerprint,
+		"commit.gpgsign":  "true",
+		"tag.gpgsign":     "true",
+	}
+}
+
+// gitIncludePath is the managed include file holding the tool's git
+// settings. Keeping them out of ~/.gitconfig means rollback is "delete
+// one file" and user customizations are never touched.
+func gitIncludePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "git", brandProduct+".inc"), nil
+}
+
+// gitSigningStep writes the signing settings into the managed include
+// file and adds a single [include] to the global config. Settings the
+// user made directly in ~/.gitconfig take precedence over an earlier
+// include, so any that conflict are shown as a diff and only removed on
+// -force or an explicit yes.
+func gitSigningStep(ctx *setupContext) error {
+	id, err := ctx.identity()
+	if err != nil {
+		return err
+	}
+	inc, err := gitIncludePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(inc), 0o755); err != nil {
+		return err
+	}
+	content := fmt.Sprintf(`# Written by %s; git signing settings live here so they can be
+# removed without touching your .gitconfig. Delete this file and the
+# matching include.path entry to roll back.
+[user]
+	signingkey = %s
+[commit]
+	gpgsign = true
+[tag]
+	gpgsign = true
+`, brandProduct, id.fingerprint)
+	if err := writeFileAtomic(inc, []byte(content), 0o644); err != nil {
+		return err
+	}
+	fmt.Println("  wrote " + inc)
+
+	if err := ensureGitInclude(inc); err != nil {
+		return err
+	}
+
+	// The include is in place; anything still differing is a direct
+	// entry in the user's own config shadowing it.
+	var drifted []string
+	desired := gitSigningValues(id.fingerprint)
+	for _, key := range []string{"user.signingkey", "commit.gpgsign", "tag.gpgsign"} {
+		got, _ := output("git", "config", "--get", key)
+		if got == desired[key] || (key == "user.signingkey" && fingerprintMatches(got, id.fingerprint)) {
+			continue
+		}
+		fmt.Printf("  %s %s is overridden in your .gitconfig:\n    yours: %s\n    card:  %s\n",
+			sym().warn, key, got, desired[key])
+		drifted = append(drifted, key)
+	}
+	if len(drifted) == 0 {
+		fmt.Printf("  %s git signs with the card key\n", sym().ok)
+		return nil
+	}
+	if !forceGitConfig && !confirm("Remove the overriding entries from your .gitconfig?", false) {
+		return fmt.Errorf("your .gitconfig overrides the card's signing settings; rerun with -force or edit it yourself")
+	}
+	for _, key := range drifted {
+		output("git", "config", "--global", "--unset-all", key)
+		fmt.Printf("  %s removed your %s override\n", sym().ok, key)
+	}
+	return nil
+}
+
+// ensureGitInclude adds the include.path entry once, no matter how
+// often setup runs.
+func ensureGitInclude(inc string) error {
+	existing, _ := output("git", "config", "--global", "--get-all", "include.path")
+	for _, p := range strings.Split(existing, "\n") {
+		if strings.TrimSpace(p) == inc {
+			return nil
+		}
+	}
+	if _, err := output("git", "config", "--global", "--add", "include.path", inc); err != nil {
+		return fmt.Errorf("adding include.path: %w", err)
+	}
+	fmt.Println("  added include.path to the global git config")
+	return nil
+}
+
+// fingerprintMatches reports whether a git signingkey value refers to
+// the given fingerprint; git accepts full fingerprints, long and short
+// key IDs, so suffix comparison is the honest test.
+func fingerprintMatches(configured, fingerprint string) bool {
+	c := strings.ToUpper(strings.TrimPrefix(configured, "0x"))
+	return c != "" && strings.HasSuffix(strings.ToUpper(fingerprint), c)
+}
@@ -0,0 +1,3 @@
+module yubikey-onboard
+
+go 1.21
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"yubikey-onboard/internal/parse"
+)
+
+// gpgIdentity is the primary key the tool operates on.
+type gpgIdentity struct {
+	fingerprint string
+	uid         string // "Name <email>"
+	email       string
+}
+
+// primaryIdentity picks the first secret key known to gpg. Onboarded
+// machines have exactly one: the key whose private half lives on the card.
+func primaryIdentity() (gpgIdentity, error) {
+	out, err := output("gpg", "--list-secret-keys", "--with-colons")
+	if err != nil {
+		return gpgIdentity{}, err
+	}
+	id, err := parse.SecretKeyIdentity(out)
+	if err != nil {
+		return gpgIdentity{}, fmt.Errorf("no secret key found; run the setup first")
+	}
+	return gpgIdentity{fingerprint: id.Fingerprint, uid: id.UID, email: id.Email}, nil
+}
+
+// exportPublicKey returns the ASCII-armored public key for fpr.
+func exportPublicKey(fpr string) (string, error) {
+	return output("gpg", "--armor", "--export", fpr)
+}
+
+// exportPublicKeyBinary returns the binary public key export for fpr.
+func exportPublicKeyBinary(fpr string) ([]byte, error) {
+	out, err := output("gpg", "--export", fpr)
+	return []byte(out), err
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"yubikey-onboard/internal/gpgenv"
+)
+
+var (
+	gpgDirsOnce sync.Once
+	gpgDirsVal  gpgenv.Dirs
+)
+
+// gpgDirs returns the gpg installation's directories, asked of gpgconf
+// once per run. When gpgconf is missing or silent the classic defaults
+// fill the gaps, so callers never see empty paths.
+func gpgDirs() gpgenv.Dirs {
+	gpgDirsOnce.Do(func() {
+		listDirs, _ := output("gpgconf", "--list-dirs")
+		listComponents, _ := output("gpgconf", "--list-components")
+		gpgDirsVal = gpgenv.Parse(listDirs, listComponents)
+		if gpgDirsVal.Home == "" {
+			if gh := os.Getenv("GNUPGHOME"); gh != "" {
+				gpgDirsVal.Home = gh
+			} else if home, err := os.UserHomeDir(); err == nil {
+				gpgDirsVal.Home = filepath.Join(home, ".gnupg")
+			}
+		}
+		if gpgDirsVal.SocketDir == "" {
+			gpgDirsVal.SocketDir = gpgDirsVal.Home
+		}
+		if gpgDirsVal.AgentSocket == "" {
+			gpgDirsVal.AgentSocket = filepath.Join(gpgDirsVal.SocketDir, "S.gpg-agent")
+		}
+		if gpgDirsVal.AgentSSHSocket == "" {
+			gpgDirsVal.AgentSSHSocket = filepath.Join(gpgDirsVal.SocketDir, "S.gpg-agent.ssh")
+		}
+	})
+	return gpgDirsVal
+}
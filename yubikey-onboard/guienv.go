@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+func init() {
+	registerStep(&step{
+		name:     "gui-env",
+		desc:     "Propagate agent environment to graphical sessions",
+		optional: false,
+		explain: "Terminals inherit the agent socket, but editors and IDEs launched " +
+			"from the desktop do not. This step wires the agent environment into " +
+			"the graphical session so signing works outside a shell too.",
+		run: guiEnvStep,
+	})
+}
+
+// guiEnvStep makes SSH_AUTH_SOCK point at the gpg-agent socket for
+// applications launched from the desktop, not just from a shell.
+// IDEs started via GNOME never read .bashrc, which is where the classic
+// "works in the terminal, not in VS Code" reports come from.
+func guiEnvStep(ctx *setupContext) error {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	if skipPersistent("graphical session environment") {
+		return nil
+	}
+	sock := gpgDirs().AgentSSHSocket
+
+	// environment.d is read by the systemd user instance at login.
+	home, _ := os.UserHomeDir()
+	envDir := filepath.Join(home, ".config", "environment.d")
+	if err := os.MkdirAll(envDir, 0o755); err != nil {
+		return err
+	}
+	conf := filepath.Join(envDir, "90-yubikey-onboard.conf")
+	if err := writeFileAtomic(conf, []byte("SSH_AUTH_SOCK="+sock+"\n"), 0o644); err != nil {
+		return err
+	}
+	fmt.Println("  wrote " + conf)
+
+	// Update the already-running session too, where possible.
+	if _, err := output("systemctl", "--user", "set-environment", "SSH_AUTH_SOCK="+sock); err == nil {
+		fmt.Println("  updated systemd user environment")
+	}
+	if haveExec("dbus-update-activation-environment") {
+		if _, err := output("dbus-update-activation-environment", "--systemd", "SSH_AUTH_SOCK="+sock); err == nil {
+			fmt.Println("  updated D-Bus activation environment")
+		}
+	}
+	fmt.Println("  log out and in once for desktop-launched applications to pick this up")
+	return nil
+}
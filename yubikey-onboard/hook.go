@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	register(&command{
+		name:    "hook",
+		summary: "install or run a fast git hook that checks the card before signing",
+		run:     runHook,
+	})
+}
+
+func runHook(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: hook install [pre-push|pre-commit] | hook run")
+	}
+	switch args[0] {
+	case "install":
+		hookType := "pre-push"
+		if len(args) > 1 {
+			hookType = args[1]
+		}
+		if hookType != "pre-push" && hookType != "pre-commit" {
+			return fmt.Errorf("unsupported hook type %q", hookType)
+		}
+		return installHook(hookType)
+	case "run":
+		return shallowSigningCheck()
+	default:
+		return fmt.Errorf("unknown hook subcommand %q", args[0])
+	}
+}
+
+func installHook(hookType string) error {
+	hooksDir, err := output("git", "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return fmt.Errorf("not inside a git repository")
+	}
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(hooksDir, hookType)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists; chain `yubikey-onboard hook run` into it manually", path)
+	}
+	script := "#!/bin/sh\n# installed by yubikey-onboard\nexec yubikey-onboard hook run\n"
+	if err := writeFileAtomic(path, []byte(script), 0o755); err != nil {
+		return err
+	}
+	fmt.Println("installed " + path)
+	return nil
+}
+
+// shallowSigningCheck is the hook body. It must be fast (it runs on
+// every push), so it only asks the agent questions, never the card
+// directly.
+func shallowSigningCheck() error {
+	if out, err := output("gpg-connect-agent", "scd serialno", "/bye"); err != nil || strings.Contains(out, "ERR") {
+		return fmt.Errorf("YubiKey not detected - plug it in and try again\n(then check with: yubikey-onboard check)")
+	}
+	keys, err := agentKeys()
+	if err != nil || len(keys) == 0 {
+		return fmt.Errorf("gpg-agent is not serving your key - try: gpgconf --kill gpg-agent\n(then check with: yubikey-onboard check)")
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+func init() {
+	checks = append(checks, check{"IDE commit signing", checkIDEs})
+}
+
+// checkIDEs verifies installed IDEs will actually sign commits. The
+// git side may be perfect while VS Code still commits unsigned because
+// its own toggle is off.
+func checkIDEs() error {
+	if err := checkVSCode(); err != nil {
+		return err
+	}
+	return checkJetBrains()
+}
+
+func vscodeSettingsPath() string {
+	home, _ := os.UserHomeDir()
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(home, "Library", "Application Support", "Code", "User", "settings.json")
+	}
+	return filepath.Join(home, ".config", "Code", "User", "settings.json")
+}
+
+func checkVSCode() error {
+	if !haveExec("code") {
+		return nil
+	}
+	path := vscodeSettingsPath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("VS Code is installed but %s is unreadable; enable git.enableCommitSigning there", path)
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		// settings.json allows comments; fall back to a substring scan.
+		if !jsonishHasTrue(string(data), "git.enableCommitSigning") {
+			return vscodeFix(path)
+		}
+		return nil
+	}
+	if v, ok := settings["git.enableCommitSigning"].(bool); !ok || !v {
+		return vscodeFix(path)
+	}
+	return nil
+}
+
+func vscodeFix(path string) error {
+	return fmt.Errorf(`VS Code will commit unsigned: set "git.enableCommitSigning": true in %s`, path)
+}
+
+func jsonishHasTrue(data, key string) bool {
+	idx := strings.Index(data, `"`+key+`"`)
+	if idx < 0 {
+		return false
+	}
+	t := strings.Index(data[idx:], "true")
+	return t >= 0 && t < 60
+}
+
+func checkJetBrains() error {
+	home, _ := os.UserHomeDir()
+	candidates := []string{
+		filepath.Join(home, ".local", "share", "JetBrains"),
+		filepath.Join(home, "Library", "Application Support", "JetBrains"),
+	}
+	for _, dir := range candidates {
+		if _, err := os.Stat(dir); err == nil {
+			// JetBrains IDEs follow git config but need the agent env;
+			// desktop launches rely on the gui-env step having run.
+			if os.Getenv("SSH_AUTH_SOCK") == "" {
+				return fmt.Errorf("JetBrains IDE detected but SSH_AUTH_SOCK is unset for the session; run the gui-env step and relogin")
+			}
+			return nil
+		}
+	}
+	return nil
+}
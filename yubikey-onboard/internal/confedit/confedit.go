@@ -0,0 +1,99 @@
+// Package confedit edits directive-style configuration files in place:
+// one "key value" line per setting, # comments, as in gpg.conf,
+// gpg-agent.conf and ssh_config. Only lines carrying the requested key
+// are touched; every other line - comments, blank lines, the user's own
+// settings - round-trips byte for byte. Appending blindly instead keeps
+// producing duplicate entries whenever a value changes, and gpg honors
+// whichever it reads first.
+package confedit
+
+import "strings"
+
+// Set returns content with key set to value exactly once. An existing
+// setting is rewritten where it stands, keeping its indentation; later
+// duplicates of the key are dropped; a missing key is appended at the
+// end. An empty value sets a bare flag line such as
+// "allow-loopback-pinentry".
+func Set(content []byte, key, value string) []byte {
+	directive := key
+	if value != "" {
+		directive += " " + value
+	}
+	lines := splitKeepingTail(content)
+	out := make([]string, 0, len(lines)+1)
+	done := false
+	for _, l := range lines {
+		if keyOf(l) == canon(key) {
+			if done {
+				continue
+			}
+			out = append(out, l[:len(l)-len(strings.TrimLeft(l, " \t"))]+directive)
+			done = true
+			continue
+		}
+		out = append(out, l)
+	}
+	if !done {
+		out = append(out, directive)
+	}
+	return joinWithNewlines(out)
+}
+
+// Unset returns content with every setting of key removed.
+func Unset(content []byte, key string) []byte {
+	lines := splitKeepingTail(content)
+	out := make([]string, 0, len(lines))
+	for _, l := range lines {
+		if keyOf(l) != canon(key) {
+			out = append(out, l)
+		}
+	}
+	return joinWithNewlines(out)
+}
+
+// Has reports whether content sets key at all.
+func Has(content []byte, key string) bool {
+	for _, l := range splitKeepingTail(content) {
+		if keyOf(l) == canon(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyOf extracts the key of a directive line, or "" for blanks and
+// comments. Both the whitespace and the "key=value" separators
+// ssh_config accepts are recognized.
+func keyOf(line string) string {
+	s := strings.TrimSpace(line)
+	if s == "" || strings.HasPrefix(s, "#") {
+		return ""
+	}
+	if i := strings.IndexAny(s, " \t="); i >= 0 {
+		s = s[:i]
+	}
+	return canon(s)
+}
+
+// canon folds a key for comparison: ssh_config keys are
+// case-insensitive, and the gpg conf files only use lowercase anyway.
+func canon(key string) string {
+	return strings.ToLower(key)
+}
+
+// splitKeepingTail splits content into lines without inventing a
+// trailing empty line for newline-terminated input.
+func splitKeepingTail(content []byte) []string {
+	s := strings.TrimSuffix(string(content), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func joinWithNewlines(lines []string) []byte {
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
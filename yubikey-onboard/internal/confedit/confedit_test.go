@@ -0,0 +1,71 @@
+package confedit
+
+import "testing"
+
+const sshStyle = `# managed partly by hand
+Host build-*
+	IdentityAgent /tmp/other.sock
+
+pinentry-program /usr/bin/pinentry-curses
+default-cache-ttl 600
+# a duplicate left by an old append
+default-cache-ttl 60
+`
+
+func TestSetReplacesInPlace(t *testing.T) {
+	got := string(Set([]byte(sshStyle), "default-cache-ttl", "300"))
+	want := `# managed partly by hand
+Host build-*
+	IdentityAgent /tmp/other.sock
+
+pinentry-program /usr/bin/pinentry-curses
+default-cache-ttl 300
+# a duplicate left by an old append
+`
+	if got != want {
+		t.Errorf("Set:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSetAppendsMissing(t *testing.T) {
+	got := string(Set([]byte("# only a comment\n"), "allow-loopback-pinentry", ""))
+	want := "# only a comment\nallow-loopback-pinentry\n"
+	if got != want {
+		t.Errorf("Set = %q, want %q", got, want)
+	}
+	if string(Set(nil, "use-agent", "")) != "use-agent\n" {
+		t.Errorf("Set on empty content = %q", Set(nil, "use-agent", ""))
+	}
+}
+
+func TestSetKeepsIndentAndCase(t *testing.T) {
+	in := "Host *\n\tIdentityAgent /old.sock\n"
+	got := string(Set([]byte(in), "identityagent", "/new.sock"))
+	want := "Host *\n\tidentityagent /new.sock\n"
+	if got != want {
+		t.Errorf("Set = %q, want %q", got, want)
+	}
+}
+
+func TestUnset(t *testing.T) {
+	got := string(Unset([]byte(sshStyle), "default-cache-ttl"))
+	want := `# managed partly by hand
+Host build-*
+	IdentityAgent /tmp/other.sock
+
+pinentry-program /usr/bin/pinentry-curses
+# a duplicate left by an old append
+`
+	if got != want {
+		t.Errorf("Unset:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHas(t *testing.T) {
+	if !Has([]byte(sshStyle), "Pinentry-Program") {
+		t.Error("Has should match case-insensitively")
+	}
+	if Has([]byte(sshStyle), "duplicate") {
+		t.Error("Has must not match inside comments")
+	}
+}
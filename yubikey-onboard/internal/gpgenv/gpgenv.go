@@ -0,0 +1,85 @@
+// Package gpgenv locates the gpg installation's directories from
+// gpgconf output instead of assuming ~/.gnupg. Distros move the socket
+// directory around (/run/user on systemd, $GNUPGHOME elsewhere), so any
+// hardcoded path is wrong somewhere.
+//
+// Like internal/parse, this package only parses text; running gpgconf
+// is the caller's job so command recording and replay keep working.
+package gpgenv
+
+import "strings"
+
+// Dirs holds the locations the tool needs. Zero fields mean gpgconf
+// did not report that entry.
+type Dirs struct {
+	Home           string // homedir: keyrings and configuration
+	SocketDir      string // socketdir: where the agent sockets live
+	AgentSocket    string // agent-socket: gpg-agent's main socket
+	AgentSSHSocket string // agent-ssh-socket: the ssh-agent protocol socket
+	Scdaemon       string // path of the scdaemon binary
+}
+
+// Parse extracts Dirs from the output of `gpgconf --list-dirs` and
+// `gpgconf --list-components`. Either input may be empty.
+func Parse(listDirs, listComponents string) Dirs {
+	var d Dirs
+	for _, line := range strings.Split(listDirs, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = unescape(value)
+		switch key {
+		case "homedir":
+			d.Home = value
+		case "socketdir":
+			d.SocketDir = value
+		case "agent-socket":
+			d.AgentSocket = value
+		case "agent-ssh-socket":
+			d.AgentSSHSocket = value
+		}
+	}
+	// Components are name:description:pgmname triples.
+	for _, line := range strings.Split(listComponents, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ":")
+		if len(fields) >= 3 && fields[0] == "scdaemon" {
+			d.Scdaemon = unescape(fields[2])
+		}
+	}
+	return d
+}
+
+// unescape decodes gpgconf's percent escapes (%3a for a colon in a
+// Windows drive letter, and friends).
+func unescape(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			if hi, ok1 := hexVal(s[i+1]); ok1 {
+				if lo, ok2 := hexVal(s[i+2]); ok2 {
+					b.WriteByte(hi<<4 | lo)
+					i += 2
+					continue
+				}
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func hexVal(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
@@ -0,0 +1,53 @@
+package gpgenv
+
+import "testing"
+
+const listDirs = `sysconfdir:/etc/gnupg
+bindir:/usr/bin
+libexecdir:/usr/lib/gnupg
+libdir:/usr/lib/x86_64-linux-gnu/gnupg
+datadir:/usr/share/gnupg
+localedir:/usr/share/locale
+socketdir:/run/user/1000/gnupg
+dirmngr-socket:/run/user/1000/gnupg/S.dirmngr
+agent-ssh-socket:/run/user/1000/gnupg/S.gpg-agent.ssh
+agent-extra-socket:/run/user/1000/gnupg/S.gpg-agent.extra
+agent-browser-socket:/run/user/1000/gnupg/S.gpg-agent.browser
+agent-socket:/run/user/1000/gnupg/S.gpg-agent
+homedir:/home/dev/.gnupg
+`
+
+const listComponents = `gpg:OpenPGP:/usr/bin/gpg
+gpg-agent:Private Keys:/usr/bin/gpg-agent
+scdaemon:Smartcards:/usr/lib/gnupg/scdaemon
+dirmngr:Network:/usr/bin/dirmngr
+`
+
+func TestParse(t *testing.T) {
+	d := Parse(listDirs, listComponents)
+	want := Dirs{
+		Home:           "/home/dev/.gnupg",
+		SocketDir:      "/run/user/1000/gnupg",
+		AgentSocket:    "/run/user/1000/gnupg/S.gpg-agent",
+		AgentSSHSocket: "/run/user/1000/gnupg/S.gpg-agent.ssh",
+		Scdaemon:       "/usr/lib/gnupg/scdaemon",
+	}
+	if d != want {
+		t.Errorf("Parse = %+v, want %+v", d, want)
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	if d := Parse("", ""); d != (Dirs{}) {
+		t.Errorf("Parse of empty input = %+v, want zero", d)
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	if got := unescape("C%3a/gnupg"); got != "C:/gnupg" {
+		t.Errorf("unescape = %q, want C:/gnupg", got)
+	}
+	if got := unescape("no-escapes"); got != "no-escapes" {
+		t.Errorf("unescape = %q, want unchanged", got)
+	}
+}
@@ -0,0 +1,197 @@
+// Package parse holds the pure-text parsers for gpg and OpenSSH output.
+// Everything here takes strings and returns structs - no exec, no IO -
+// so the parsers can be fuzzed and pinned against golden output from
+// the gpg and OpenSSH versions in the fleet.
+package parse
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Identity is the primary key from gpg --list-secret-keys --with-colons.
+type Identity struct {
+	Fingerprint string
+	UID         string // "Name <email>"
+	Email       string
+}
+
+// SecretKeyIdentity picks the first secret key out of colon-format
+// listing output.
+func SecretKeyIdentity(colons string) (Identity, error) {
+	var id Identity
+	for _, line := range strings.Split(colons, "\n") {
+		f := strings.Split(line, ":")
+		switch f[0] {
+		case "fpr":
+			if id.Fingerprint == "" && len(f) > 9 {
+				id.Fingerprint = f[9]
+			}
+		case "uid":
+			if id.UID == "" && len(f) > 9 {
+				id.UID = f[9]
+				if i := strings.IndexByte(id.UID, '<'); i >= 0 {
+					id.Email = strings.TrimRight(id.UID[i+1:], ">")
+				}
+			}
+		}
+	}
+	if id.Fingerprint == "" {
+		return Identity{}, fmt.Errorf("no secret key in listing")
+	}
+	return id, nil
+}
+
+// CardStatus is the human-format output of gpg --card-status. gpg has
+// no colon format for most of these fields, so the human output is the
+// interface, quirks and all.
+type CardStatus struct {
+	Serial   string
+	Holder   string
+	Language string
+	URL      string
+	// Retries holds the user, reset and admin PIN counters.
+	Retries     [3]int
+	HaveRetries bool
+}
+
+// CardStatusOutput parses gpg --card-status. Unknown lines are skipped:
+// the format gains lines across gpg versions but the labels parsed here
+// have been stable since 2.1.
+func CardStatusOutput(out string) CardStatus {
+	var cs CardStatus
+	for _, line := range strings.Split(out, "\n") {
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		// Labels are dot-padded to the colon ("Serial number ....:");
+		// strip the padding before matching.
+		switch strings.TrimSpace(strings.TrimRight(strings.TrimSpace(k), ".")) {
+		case "Serial number":
+			cs.Serial = v
+		case "Name of cardholder":
+			cs.Holder = notSetToEmpty(v)
+		case "Language prefs":
+			cs.Language = notSetToEmpty(v)
+		case "URL of public key":
+			cs.URL = notSetToEmpty(v)
+		case "PIN retry counter":
+			var u, r, a int
+			if n, _ := fmt.Sscanf(v, "%d %d %d", &u, &r, &a); n == 3 {
+				cs.Retries = [3]int{u, r, a}
+				cs.HaveRetries = true
+			}
+		}
+	}
+	return cs
+}
+
+func notSetToEmpty(v string) string {
+	if v == "[not set]" {
+		return ""
+	}
+	return v
+}
+
+// ClearsignedBody extracts the signed text of a clearsigned message:
+// everything between the Hash headers and the signature block, with
+// gpg's dash-escaping undone. Content outside the signed section is
+// discarded - it was never covered by the signature.
+func ClearsignedBody(signed string) (string, error) {
+	lines := strings.Split(signed, "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "-----BEGIN PGP SIGNED MESSAGE-----" {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return "", fmt.Errorf("no clearsigned message found")
+	}
+	// Skip the armor headers (Hash: ...) up to the blank separator.
+	for start < len(lines) && strings.TrimSpace(lines[start]) != "" {
+		start++
+	}
+	start++
+	var body []string
+	for _, line := range lines[start:] {
+		if strings.TrimSpace(line) == "-----BEGIN PGP SIGNATURE-----" {
+			return strings.Join(body, "\n"), nil
+		}
+		body = append(body, strings.TrimPrefix(line, "- "))
+	}
+	return "", fmt.Errorf("clearsigned message has no signature block")
+}
+
+// ValidSigFingerprint returns the primary key fingerprint from a
+// VALIDSIG line of gpg --status-fd output, or "" when the status
+// carries none. VALIDSIG's last field is the primary key even when a
+// subkey made the signature.
+func ValidSigFingerprint(status string) string {
+	for _, line := range strings.Split(status, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == "[GNUPG:]" && fields[1] == "VALIDSIG" {
+			return fields[len(fields)-1]
+		}
+	}
+	return ""
+}
+
+// KeyCreatedFingerprint returns the fingerprint from the KEY_CREATED
+// line of gpg --status-fd output after key generation, or "" when no
+// key was created. Unlike a keyring lookup afterwards, this names the
+// key this very invocation made.
+func KeyCreatedFingerprint(status string) string {
+	for _, line := range strings.Split(status, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 4 && fields[0] == "[GNUPG:]" && fields[1] == "KEY_CREATED" {
+			return fields[3]
+		}
+	}
+	return ""
+}
+
+// AgentKey is one entry of ssh-add -L.
+type AgentKey struct {
+	Algo    string
+	Blob    []byte // raw wire-format key
+	Comment string
+}
+
+// AgentKeys parses the full ssh-add -L listing.
+func AgentKeys(out string) ([]AgentKey, error) {
+	var keys []AgentKey
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		k, err := PublicKeyLine(line)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// PublicKeyLine parses one authorized_keys-style line.
+func PublicKeyLine(line string) (AgentKey, error) {
+	fields := strings.SplitN(line, " ", 3)
+	if len(fields) < 2 {
+		return AgentKey{}, fmt.Errorf("malformed public key line: %q", line)
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return AgentKey{}, fmt.Errorf("malformed public key base64: %v", err)
+	}
+	k := AgentKey{Algo: fields[0], Blob: blob}
+	if len(fields) == 3 {
+		k.Comment = strings.TrimSpace(fields[2])
+	}
+	return k, nil
+}
@@ -0,0 +1,180 @@
+package parse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf8"
+)
+
+func golden(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCardStatusGPG22(t *testing.T) {
+	cs := CardStatusOutput(golden(t, "card-status-gpg22.txt"))
+	if cs.Serial != "12345678" {
+		t.Errorf("Serial = %q, want 12345678", cs.Serial)
+	}
+	if cs.Holder != "Ada Lovelace" {
+		t.Errorf("Holder = %q, want Ada Lovelace", cs.Holder)
+	}
+	if cs.Language != "en" {
+		t.Errorf("Language = %q, want en", cs.Language)
+	}
+	if cs.URL == "" {
+		t.Error("URL not parsed")
+	}
+	if !cs.HaveRetries || cs.Retries != [3]int{3, 0, 3} {
+		t.Errorf("Retries = %v (have=%v), want [3 0 3]", cs.Retries, cs.HaveRetries)
+	}
+}
+
+func TestCardStatusGPG24Unset(t *testing.T) {
+	cs := CardStatusOutput(golden(t, "card-status-gpg24.txt"))
+	if cs.Serial != "09876543" {
+		t.Errorf("Serial = %q, want 09876543", cs.Serial)
+	}
+	// [not set] fields must come back empty, not as the literal.
+	if cs.Holder != "" || cs.Language != "" || cs.URL != "" {
+		t.Errorf("unset fields leaked: holder=%q lang=%q url=%q", cs.Holder, cs.Language, cs.URL)
+	}
+	if !cs.HaveRetries || cs.Retries != [3]int{2, 0, 1} {
+		t.Errorf("Retries = %v, want [2 0 1]", cs.Retries)
+	}
+}
+
+func TestSecretKeyIdentity(t *testing.T) {
+	id, err := SecretKeyIdentity(golden(t, "secret-keys.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Fingerprint != "AAAABBBBCCCCDDDDEEEEFFFF0000111122223333" {
+		t.Errorf("Fingerprint = %q", id.Fingerprint)
+	}
+	if id.Email != "ada@example.com" {
+		t.Errorf("Email = %q, want ada@example.com", id.Email)
+	}
+}
+
+func TestSecretKeyIdentityEmpty(t *testing.T) {
+	if _, err := SecretKeyIdentity(""); err == nil {
+		t.Error("want an error for an empty listing")
+	}
+}
+
+func TestAgentKeys(t *testing.T) {
+	keys, err := AgentKeys(golden(t, "ssh-add-l.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("got %d keys, want 2", len(keys))
+	}
+	if keys[0].Algo != "ssh-ed25519" || keys[0].Comment != "cardno:12 345 678" {
+		t.Errorf("key 0 = %q %q", keys[0].Algo, keys[0].Comment)
+	}
+	if keys[1].Algo != "ssh-rsa" || keys[1].Comment != "ada@laptop" {
+		t.Errorf("key 1 = %q %q", keys[1].Algo, keys[1].Comment)
+	}
+}
+
+func TestClearsignedBody(t *testing.T) {
+	signed := `prefix the server added; not covered by the signature
+AAAABBBBCCCCDDDDEEEEFFFF0000111122223333
+-----BEGIN PGP SIGNED MESSAGE-----
+Hash: SHA512
+
+1111222233334444555566667777888899990000
+- -- dash-escaped line
+-----BEGIN PGP SIGNATURE-----
+
+iQEzBAEBCgAdFiEE...
+-----END PGP SIGNATURE-----
+`
+	body, err := ClearsignedBody(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "1111222233334444555566667777888899990000\n-- dash-escaped line"
+	if body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+	if _, err := ClearsignedBody("no armor here"); err == nil {
+		t.Error("want an error without a signed message")
+	}
+	if _, err := ClearsignedBody("-----BEGIN PGP SIGNED MESSAGE-----\n\ntruncated"); err == nil {
+		t.Error("want an error without a signature block")
+	}
+}
+
+func TestValidSigFingerprint(t *testing.T) {
+	status := `[GNUPG:] NEWSIG
+[GNUPG:] GOODSIG 1122334455667788 Ada <ada@example.com>
+[GNUPG:] VALIDSIG 9999000011112222333344441122334455667788 2026-08-31 1756600000 0 4 0 22 10 00 AAAABBBBCCCCDDDDEEEEFFFF0000111122223333
+`
+	// The last VALIDSIG field is the primary key, not the signing subkey.
+	if got := ValidSigFingerprint(status); got != "AAAABBBBCCCCDDDDEEEEFFFF0000111122223333" {
+		t.Errorf("fingerprint = %q", got)
+	}
+	if got := ValidSigFingerprint("[GNUPG:] GOODSIG 1122 Ada"); got != "" {
+		t.Errorf("fingerprint without VALIDSIG = %q, want empty", got)
+	}
+}
+
+func TestKeyCreatedFingerprint(t *testing.T) {
+	status := `[GNUPG:] KEY_CONSIDERED AAAABBBBCCCCDDDDEEEEFFFF0000111122223333 0
+[GNUPG:] KEY_CREATED P 1111222233334444555566667777888899990000
+`
+	if got := KeyCreatedFingerprint(status); got != "1111222233334444555566667777888899990000" {
+		t.Errorf("fingerprint = %q", got)
+	}
+	if got := KeyCreatedFingerprint("gpg: key generation completed"); got != "" {
+		t.Errorf("fingerprint without KEY_CREATED = %q, want empty", got)
+	}
+}
+
+func FuzzCardStatusOutput(f *testing.F) {
+	f.Add("Serial number ....: 123\nPIN retry counter : 3 0 3\n")
+	f.Add("Name of cardholder: [not set]")
+	f.Add(":::")
+	f.Fuzz(func(t *testing.T, out string) {
+		cs := CardStatusOutput(out)
+		if cs.Holder == "[not set]" || cs.URL == "[not set]" {
+			t.Error("[not set] must not survive parsing")
+		}
+	})
+}
+
+func FuzzSecretKeyIdentity(f *testing.F) {
+	f.Add("fpr:::::::::ABCD:\nuid:::::::::Ada <a@b>:\n")
+	f.Add("uid:")
+	f.Fuzz(func(t *testing.T, colons string) {
+		id, err := SecretKeyIdentity(colons)
+		if err == nil && id.Fingerprint == "" {
+			t.Error("success with empty fingerprint")
+		}
+	})
+}
+
+func FuzzPublicKeyLine(f *testing.F) {
+	f.Add("ssh-ed25519 AAAA comment")
+	f.Add("x y")
+	f.Fuzz(func(t *testing.T, line string) {
+		k, err := PublicKeyLine(line)
+		if err != nil {
+			return
+		}
+		if k.Algo == "" {
+			t.Error("parsed key has no algorithm")
+		}
+		if !utf8.ValidString(k.Algo) && utf8.ValidString(line) {
+			t.Error("algo corrupted relative to input")
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+)
+
+func init() {
+	register(&command{
+		name:    "whose",
+		summary: "look up the owner of a card serial in the asset inventory",
+		run:     runWhose,
+	})
+	registerStep(&step{
+		name:     "inventory",
+		desc:     "Register this card and laptop with the asset inventory",
+		optional: true,
+		after:    []string{"pin"},
+		run:      inventoryStep,
+	})
+}
+
+// inventoryRecord is the (serial, employee, hostname) triple the
+// inventory keeps, so a key found in a meeting room gets back to its
+// owner.
+type inventoryRecord struct {
+	Serial   string `json:"serial"`
+	Employee string `json:"employee"`
+	Hostname string `json:"hostname"`
+}
+
+func inventoryStep(ctx *setupContext) error {
+	url := org().InventoryURL
+	if url == "" {
+		fmt.Println("  no inventory_url configured; skipping")
+		return nil
+	}
+	serial, err := cardSerial()
+	if err != nil {
+		return err
+	}
+	id, err := ctx.identity()
+	if err != nil {
+		return err
+	}
+	rec := inventoryRecord{
+		Serial:   serial,
+		Employee: id.email,
+		Hostname: hostnameOr(""),
+	}
+	body, _ := json.Marshal(rec)
+	resp, err := http.Post(url+"/register", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("inventory registration: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("inventory registration: HTTP %s", resp.Status)
+	}
+	fmt.Printf("  registered serial %s to %s (%s)\n", serial, rec.Employee, rec.Hostname)
+	return nil
+}
+
+// runWhose answers the admin question behind every found key: whose is
+// this. With no -serial it asks the inserted card.
+func runWhose(args []string) error {
+	fs := flag.NewFlagSet("whose", flag.ContinueOnError)
+	serial := fs.String("serial", "", "card serial to look up (default: the inserted card)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	url := org().InventoryURL
+	if url == "" {
+		return fmt.Errorf("no inventory_url configured in org.json")
+	}
+	s := *serial
+	if s == "" {
+		var err error
+		if s, err = cardSerial(); err != nil {
+			return fmt.Errorf("no -serial given and no card inserted: %w", err)
+		}
+	}
+	resp, err := http.Get(url + "/lookup?serial=" + s)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("serial %s is not registered", s)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("inventory lookup: HTTP %s", resp.Status)
+	}
+	var rec inventoryRecord
+	if err := json.NewDecoder(resp.Body).Decode(&rec); err != nil {
+		return err
+	}
+	fmt.Printf("Serial %s belongs to %s (last seen on %s)\n", rec.Serial, rec.Employee, rec.Hostname)
+	return nil
+}
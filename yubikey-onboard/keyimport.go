@@ -0,0 +1,247 @@
+package main
+
+import (
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	register(&command{
+		name:    "import-key",
+		summary: "import a public key file, with an interactive picker",
+		run:     runImportKey,
+	})
+}
+
+func runImportKey(args []string) error {
+	fs := flag.NewFlagSet("import-key", flag.ContinueOnError)
+	keyPath := fs.String("key", "", "path to the .key/.asc file (omit for the interactive picker)")
+	keyStdin := fs.Bool("key-stdin", false, "read the armored key from stdin instead of a file")
+	paste := fs.Bool("paste", false, "paste the armored key at an interactive prompt")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *keyStdin || *paste {
+		armored, err := readArmoredKey(*paste)
+		if err != nil {
+			return err
+		}
+		return importKeyBytes(armored)
+	}
+	path := *keyPath
+	if path == "" {
+		var err error
+		if path, err = pickKeyFile(); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if path, err = resolveExistingFile(cleanDraggedPath(path)); err != nil {
+			return err
+		}
+	}
+	return importKeyFile(path)
+}
+
+// cleanDraggedPath undoes what terminals do to a drag-and-dropped file:
+// surrounding quotes and backslash-escaped spaces. A path typed by hand
+// passes through unchanged.
+func cleanDraggedPath(p string) string {
+	p = strings.TrimSpace(p)
+	if len(p) >= 2 && (p[0] == '\'' || p[0] == '"') && p[len(p)-1] == p[0] {
+		p = p[1 : len(p)-1]
+	}
+	p = strings.ReplaceAll(p, "\\ ", " ")
+	p = strings.ReplaceAll(p, "\\(", "(")
+	p = strings.ReplaceAll(p, "\\)", ")")
+	return p
+}
+
+// pickKeyFile is a minimal file browser for people who cannot type
+// paths: number to descend or select, empty to accept a dragged path.
+// It starts where dropped key files usually land.
+func pickKeyFile() (string, error) {
+	dir, _ := os.UserHomeDir()
+	if d := filepath.Join(dir, "Downloads"); dirExists(d) {
+		dir = d
+	}
+	for {
+		entries, err := keyPickerEntries(dir)
+		if err != nil {
+			return "", err
+		}
+		fmt.Println("\n" + dir)
+		for i, e := range entries {
+			marker := " "
+			if e.IsDir() {
+				marker = "/"
+			}
+			fmt.Printf("  %2d %s%s\n", i+1, e.Name(), marker)
+		}
+		answer := promptString("Number to open, or drag the file into the terminal (.. goes up):", "")
+		switch {
+		case answer == "":
+			continue
+		case answer == "..":
+			dir = filepath.Dir(dir)
+		default:
+			if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(entries) {
+				e := entries[n-1]
+				next := filepath.Join(dir, e.Name())
+				if e.IsDir() {
+					dir = next
+					continue
+				}
+				return next, nil
+			}
+			// Anything else is a typed or dragged path.
+			if p, err := resolveExistingFile(cleanDraggedPath(answer)); err == nil {
+				return p, nil
+			} else {
+				fmt.Println("  " + err.Error())
+			}
+		}
+	}
+}
+
+// keyPickerEntries lists a directory with directories first and
+// dotfiles hidden, key-ish files before other files.
+func keyPickerEntries(dir string) ([]os.DirEntry, error) {
+	all, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []os.DirEntry
+	for _, e := range all {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].IsDir() != entries[j].IsDir() {
+			return entries[i].IsDir()
+		}
+		return keyish(entries[i].Name()) && !keyish(entries[j].Name())
+	})
+	return entries, nil
+}
+
+func keyish(name string) bool {
+	for _, ext := range []string{".key", ".asc", ".gpg", ".pgp"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyKeyChecksum compares the file against the sha256 the admins
+// recorded in pass under yubikey-onboard/checksums/<filename>. A
+// missing entry (or no pass at all) is not an error - the check exists
+// to catch truncated downloads of keys that do have a recorded hash,
+// which otherwise surface as baffling "no valid OpenPGP data" imports.
+func verifyKeyChecksum(path string) error {
+	if !haveExec("pass") {
+		return nil
+	}
+	expected, err := output("pass", "show", "yubikey-onboard/checksums/"+filepath.Base(path))
+	if err != nil {
+		return nil // no recorded checksum for this file
+	}
+	expected = strings.ToLower(strings.TrimSpace(strings.Split(expected, "\n")[0]))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	got := fmt.Sprintf("%x", sha256.Sum256(data))
+	if got != expected {
+		return fmt.Errorf("checksum mismatch for %s: got %s, pass repo says %s - the file is corrupt or truncated, download it again", path, got, expected)
+	}
+	fmt.Println("  checksum matches the pass repo")
+	return nil
+}
+
+func dirExists(p string) bool {
+	fi, err := os.Stat(p)
+	return err == nil && fi.IsDir()
+}
+
+// readArmoredKey collects an ASCII-armored key from stdin. In paste
+// mode it prompts and stops at the END line, so a key copied out of
+// chat goes straight in without a temp file.
+func readArmoredKey(interactive bool) ([]byte, error) {
+	if interactive {
+		fmt.Println("Paste the public key, ending with the -----END PGP PUBLIC KEY BLOCK----- line:")
+	}
+	var lines []string
+	started := false
+	for {
+		line, err := stdin.ReadString('\n')
+		if line != "" {
+			trimmed := strings.TrimRight(line, "\r\n")
+			if !started {
+				if strings.HasPrefix(trimmed, "-----BEGIN PGP PUBLIC KEY BLOCK-----") {
+					started = true
+				} else if trimmed != "" && interactive {
+					fmt.Println("  (waiting for the BEGIN line)")
+				}
+			}
+			if started {
+				lines = append(lines, trimmed)
+				if strings.HasPrefix(trimmed, "-----END PGP PUBLIC KEY BLOCK-----") {
+					break
+				}
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if !started {
+		return nil, fmt.Errorf("no PGP PUBLIC KEY BLOCK found in the input")
+	}
+	last := lines[len(lines)-1]
+	if !strings.HasPrefix(last, "-----END PGP PUBLIC KEY BLOCK-----") {
+		return nil, fmt.Errorf("key block is truncated (no END line); copy it again completely")
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// importKeyBytes validates and imports an in-memory armored key.
+func importKeyBytes(armored []byte) error {
+	tmp, err := os.CreateTemp("", "yko-import")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	tmp.Write(armored)
+	tmp.Close()
+	// A dry-run first gives a clean "that was not a key" message
+	// instead of half-imported garbage.
+	if _, err := output("gpg", "--import", "--import-options", "show-only", tmp.Name()); err != nil {
+		return fmt.Errorf("the pasted text is not a valid public key: %w", err)
+	}
+	return importKeyFile(tmp.Name())
+}
+
+// importKeyFile imports one public key file into the keyring, checking
+// it against the pass repo's recorded checksum first when one exists.
+func importKeyFile(path string) error {
+	if err := verifyKeyChecksum(path); err != nil {
+		return err
+	}
+	if out, err := output("gpg", "--import", path); err != nil {
+		return fmt.Errorf("import failed: %v: %s", err, out)
+	}
+	// Link any imported key to the inserted card, as fetch-key does.
+	output("gpg", "--card-status")
+	fmt.Println("Imported " + path)
+	return nil
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"math/big"
+	"strings"
+	"yubikey-onboard/internal/parse"
+)
+
+func init() {
+	register(&command{
+		name:    "show-keys",
+		summary: "print the SSH public key served by gpg-agent",
+		run:     runShowKeys,
+	})
+}
+
+func runShowKeys(args []string) error {
+	fs := flag.NewFlagSet("show-keys", flag.ContinueOnError)
+	format := fs.String("format", "", "SSH key output format: openssh, rfc4716, pkcs8 or authorized_keys")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// Without an explicit format, print the full overview people need
+	// when filling in forms later: SSH key, GPG key, fingerprint, serial.
+	if *format == "" {
+		return showKeyOverview()
+	}
+
+	keys, err := cardAgentKeys()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("ssh-add -L returned no keys; is gpg-agent running with SSH support enabled?")
+	}
+	for _, k := range keys {
+		s, err := formatKey(k, *format)
+		if err != nil {
+			return err
+		}
+		// Wrap long one-line formats for humans; pipes get the raw line.
+		if stdoutIsTerminal() && !strings.Contains(s, "\n") {
+			s = wrapLine(s, termWidth())
+		}
+		fmt.Println(s)
+	}
+	return nil
+}
+
+func showKeyOverview() error {
+	id, err := primaryIdentity()
+	if err != nil {
+		return err
+	}
+	fmt.Println("GPG fingerprint: " + id.fingerprint)
+	if serial, err := cardSerial(); err == nil {
+		fmt.Println("Card serial:     " + serial)
+	} else {
+		fmt.Println("Card serial:     (no card detected)")
+	}
+
+	fmt.Println("\nSSH public key (from the card):")
+	keys, err := cardAgentKeys()
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		s, _ := formatKey(k, "openssh")
+		if stdoutIsTerminal() {
+			s = wrapLine(s, termWidth())
+		}
+		fmt.Println(s)
+	}
+
+	armored, err := exportPublicKey(id.fingerprint)
+	if err != nil {
+		return err
+	}
+	fmt.Println("\nGPG public key:")
+	fmt.Println(armored)
+	return nil
+}
+
+// cardSerial returns the serial number of the inserted card.
+func cardSerial() (string, error) {
+	out, err := output("gpg", "--card-status")
+	if err != nil {
+		return "", err
+	}
+	cs := parse.CardStatusOutput(out)
+	if cs.Serial == "" {
+		return "", fmt.Errorf("gpg --card-status did not report a serial number")
+	}
+	return cs.Serial, nil
+}
+
+// cardAgentKeys returns the agent identities belonging to the inserted
+// card. Other loaded keys (laptop keys, forwarded agents) are ignored;
+// with several cards' keys loaded and no way to tell them apart, it
+// errors rather than guessing.
+func cardAgentKeys() ([]sshKey, error) {
+	keys, err := agentKeys()
+	if err != nil {
+		return nil, err
+	}
+	var card []sshKey
+	cardnos := map[string]bool{}
+	for _, k := range keys {
+		if no := cardnoFromComment(k.comment); no != "" {
+			card = append(card, k)
+			cardnos[no] = true
+		}
+	}
+	if len(card) == 0 {
+		return keys, nil // old gpg versions omit the comment
+	}
+	if serial, err := cardSerial(); err == nil {
+		var match []sshKey
+		for _, k := range card {
+			if sameCard(cardnoFromComment(k.comment), serial) {
+				match = append(match, k)
+			}
+		}
+		if len(match) > 0 {
+			return match, nil
+		}
+	}
+	if len(cardnos) > 1 {
+		return nil, fmt.Errorf("the agent lists keys from %d different cards and none matches the inserted one; unplug the cards you are not onboarding", len(cardnos))
+	}
+	return card, nil
+}
+
+func cardnoFromComment(comment string) string {
+	_, no, ok := strings.Cut(comment, "cardno:")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(no)
+}
+
+// sameCard compares a cardno comment with a gpg --card-status serial,
+// both reduced to digits (formats differ in spacing and zero padding).
+func sameCard(cardno, serial string) bool {
+	a := digitsOnly(cardno)
+	b := digitsOnly(serial)
+	if a == "" || b == "" {
+		return false
+	}
+	return strings.HasSuffix(a, strings.TrimLeft(b, "0")) || strings.HasSuffix(b, strings.TrimLeft(a, "0"))
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// sshKey is one entry from ssh-add -L.
+type sshKey struct {
+	algo    string
+	blob    []byte // raw wire-format key
+	comment string
+}
+
+func agentKeys() ([]sshKey, error) {
+	out, err := output("ssh-add", "-L")
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parse.AgentKeys(out)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]sshKey, len(parsed))
+	for i, k := range parsed {
+		keys[i] = sshKey{algo: k.Algo, blob: k.Blob, comment: k.Comment}
+	}
+	return keys, nil
+}
+
+func parseSSHPublicKey(line string) (sshKey, error) {
+	k, err := parse.PublicKeyLine(line)
+	if err != nil {
+		return sshKey{}, err
+	}
+	return sshKey{algo: k.Algo, blob: k.Blob, comment: k.Comment}, nil
+}
+
+func formatKey(k sshKey, format string) (string, error) {
+	switch format {
+	case "openssh", "authorized_keys":
+		// authorized_keys lines are the openssh encoding; keep both names
+		// since that is what people search the help for.
+		s := k.algo + " " + base64.StdEncoding.EncodeToString(k.blob)
+		if k.comment != "" {
+			s += " " + k.comment
+		}
+		return s, nil
+	case "rfc4716":
+		return formatRFC4716(k), nil
+	case "pkcs8":
+		return formatPKCS8(k)
+	default:
+		return "", fmt.Errorf("unknown format %q (want openssh, rfc4716, pkcs8 or authorized_keys)", format)
+	}
+}
+
+func formatRFC4716(k sshKey) string {
+	var b strings.Builder
+	b.WriteString("---- BEGIN SSH2 PUBLIC KEY ----\n")
+	if k.comment != "" {
+		b.WriteString("Comment: \"" + k.comment + "\"\n")
+	}
+	enc := base64.StdEncoding.EncodeToString(k.blob)
+	for len(enc) > 70 {
+		b.WriteString(enc[:70] + "\n")
+		enc = enc[70:]
+	}
+	b.WriteString(enc + "\n")
+	b.WriteString("---- END SSH2 PUBLIC KEY ----")
+	return b.String()
+}
+
+func formatPKCS8(k sshKey) (string, error) {
+	pub, err := sshBlobToPublicKey(k.blob)
+	if err != nil {
+		return "", err
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	block := &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	return strings.TrimRight(string(pem.EncodeToMemory(block)), "\n"), nil
+}
+
+// sshBlobToPublicKey decodes the SSH wire-format key into a crypto public key.
+func sshBlobToPublicKey(blob []byte) (pub interface{}, err error) {
+	r := &wireReader{buf: blob}
+	algo := string(r.bytes())
+	switch algo {
+	case "ssh-rsa":
+		e := r.bigInt()
+		n := r.bigInt()
+		if r.err != nil {
+			return nil, r.err
+		}
+		if !e.IsInt64() {
+			return nil, fmt.Errorf("rsa exponent out of range")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "ssh-ed25519":
+		raw := r.bytes()
+		if r.err != nil {
+			return nil, r.err
+		}
+		if len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("bad ed25519 key length %d", len(raw))
+		}
+		return ed25519.PublicKey(raw), nil
+	case "ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521":
+		curveName := string(r.bytes())
+		point := r.bytes()
+		if r.err != nil {
+			return nil, r.err
+		}
+		var curve elliptic.Curve
+		switch curveName {
+		case "nistp256":
+			curve = elliptic.P256()
+		case "nistp384":
+			curve = elliptic.P384()
+		case "nistp521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unknown curve %q", curveName)
+		}
+		x, y := elliptic.Unmarshal(curve, point)
+		if x == nil {
+			return nil, fmt.Errorf("malformed ecdsa point")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("cannot convert %q keys to pkcs8", algo)
+	}
+}
+
+// wireReader reads SSH wire-format length-prefixed fields.
+type wireReader struct {
+	buf []byte
+	err error
+}
+
+func (r *wireReader) bytes() []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.buf) < 4 {
+		r.err = fmt.Errorf("truncated key blob")
+		return nil
+	}
+	n := binary.BigEndian.Uint32(r.buf)
+	r.buf = r.buf[4:]
+	if uint32(len(r.buf)) < n {
+		r.err = fmt.Errorf("truncated key blob")
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *wireReader) bigInt() *big.Int {
+	return new(big.Int).SetBytes(r.bytes())
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"yubikey-onboard/internal/parse"
+)
+
+func init() {
+	register(&command{
+		name:    "loan",
+		summary: "provision a short-lived key for a visitor or loaner laptop",
+		run:     runLoan,
+	})
+	register(&command{
+		name:    "return",
+		summary: "undo a loaner provisioning and wipe its configuration",
+		run:     runReturn,
+	})
+}
+
+// loanRecord captures what `loan` set up so `return` can take exactly
+// that apart again, no matter who runs it.
+type loanRecord struct {
+	Fingerprint string    `json:"fingerprint"`
+	Email       string    `json:"email"`
+	Expires     time.Time `json:"expires"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func runLoan(args []string) error {
+	fs := flag.NewFlagSet("loan", flag.ContinueOnError)
+	name := fs.String("name", "", "visitor's name (required)")
+	email := fs.String("email", "", "visitor's email (required)")
+	days := fs.Int("days", 14, "key validity in days")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *email == "" {
+		return fmt.Errorf("usage: loan -name NAME -email EMAIL [-days N]")
+	}
+	*email = completeEmail(*email)
+
+	st, err := loadState()
+	if err != nil {
+		return err
+	}
+	if st.Loan != nil {
+		return fmt.Errorf("an active loan for %s exists (expires %s); run `return` first",
+			st.Loan.Email, st.Loan.Expires.Format("2006-01-02"))
+	}
+
+	// A loaner key lives on disk, not on a card: visitors get signing
+	// that expires on its own, not hardware provisioning.
+	expiry := fmt.Sprintf("%dd", *days)
+	status, err := output("gpg", "--batch", "--status-fd", "1", "--quick-generate-key",
+		fmt.Sprintf("%s (loaner) <%s>", *name, *email), "ed25519", "sign", expiry)
+	if err != nil {
+		return fmt.Errorf("key generation failed: %w", err)
+	}
+	// Take the fingerprint from this invocation's KEY_CREATED status. A
+	// keyring lookup by email could pick up a leftover key from an
+	// earlier loan, and `return` deletes whatever gets recorded here.
+	fpr := parse.KeyCreatedFingerprint(status)
+	if fpr == "" {
+		return fmt.Errorf("gpg reported no KEY_CREATED for the new loaner key")
+	}
+	// Marginal trust only: colleagues' tooling should show loaner
+	// signatures as valid-ish, never as fully trusted identities.
+	ownertrust := strings.NewReader(fpr + ":3:\n")
+	cmd := exec.Command("gpg", "--import-ownertrust")
+	cmd.Stdin = ownertrust
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("setting ownertrust: %w", err)
+	}
+
+	if _, err := output("git", "config", "--global", "user.signingkey", fpr); err != nil {
+		return err
+	}
+	if _, err := output("git", "config", "--global", "commit.gpgsign", "true"); err != nil {
+		return err
+	}
+
+	st.Loan = &loanRecord{
+		Fingerprint: fpr,
+		Email:       *email,
+		Expires:     now().AddDate(0, 0, *days),
+		CreatedAt:   now(),
+	}
+	if err := saveState(st); err != nil {
+		return err
+	}
+	auditLog(fmt.Sprintf("loan: provisioned %s for %s, expires %s", fpr, *email, expiry))
+	fmt.Printf("Loaner key %s ready for %s, expires %s; run `return` when handing the laptop back\n",
+		fpr, *email, st.Loan.Expires.Format("2006-01-02"))
+	return nil
+}
+
+func runReturn(args []string) error {
+	st, err := loadState()
+	if err != nil {
+		return err
+	}
+	if st.Loan == nil {
+		return fmt.Errorf("no active loan recorded on this machine")
+	}
+	loan := st.Loan
+
+	// Delete secret material first; a loaner laptop must not keep a
+	// usable signing key after hand-back.
+	if _, err := output("gpg", "--batch", "--yes", "--delete-secret-and-public-key", loan.Fingerprint); err != nil {
+		return fmt.Errorf("deleting the loaner key: %w", err)
+	}
+	output("git", "config", "--global", "--unset", "user.signingkey")
+	output("git", "config", "--global", "--unset", "commit.gpgsign")
+	output("gpgconf", "--kill", "gpg-agent")
+
+	st.Loan = nil
+	if err := saveState(st); err != nil {
+		return err
+	}
+	auditLog(fmt.Sprintf("return: wiped loaner key %s (%s)", loan.Fingerprint, loan.Email))
+	fmt.Printf("Loaner key %s wiped and signing config removed\n", loan.Fingerprint)
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+func init() {
+	checks = append(checks, check{"SELinux/AppArmor denials", checkMACDenials})
+}
+
+// checkMACDenials looks for mandatory-access-control denials against
+// the gpg stack; on enforcing Fedora/Ubuntu profiles the agent socket
+// or scdaemon is sometimes blocked silently.
+func checkMACDenials() error {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	if mode, err := output("getenforce"); err == nil && mode == "Enforcing" {
+		if denials := selinuxDenials(); denials != "" {
+			return fmt.Errorf("SELinux denials against the gpg stack:\n%s\nfix: sudo ausearch -m avc -c scdaemon | audit2allow -M yko-gpg && sudo semodule -i yko-gpg.pp", denials)
+		}
+	}
+	if haveExec("aa-status") {
+		if denials := apparmorDenials(); denials != "" {
+			return fmt.Errorf("AppArmor denials against the gpg stack:\n%s\nfix: sudo aa-complain /etc/apparmor.d/*gpg* (then report it to IT)", denials)
+		}
+	}
+	return nil
+}
+
+// gpgProcesses are the binaries whose denials we care about.
+var gpgProcesses = []string{"gpg-agent", "scdaemon", "pcscd", "pinentry"}
+
+func selinuxDenials() string {
+	out, err := output("ausearch", "-m", "avc", "-ts", "recent")
+	if err != nil {
+		return "" // ausearch missing or needs root; nothing to report
+	}
+	return filterDenialLines(out)
+}
+
+func apparmorDenials() string {
+	out, err := output("journalctl", "-k", "--since", "-1h", "--no-pager", "-q")
+	if err != nil {
+		return ""
+	}
+	var hits []string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "apparmor=\"DENIED\"") || strings.Contains(line, "apparmor=DENIED") {
+			hits = append(hits, line)
+		}
+	}
+	return filterDenialLines(strings.Join(hits, "\n"))
+}
+
+func filterDenialLines(out string) string {
+	var hits []string
+	for _, line := range strings.Split(out, "\n") {
+		for _, proc := range gpgProcesses {
+			if strings.Contains(line, proc) {
+				hits = append(hits, "  "+strings.TrimSpace(line))
+				break
+			}
+		}
+	}
+	if len(hits) > 3 {
+		hits = hits[len(hits)-3:]
+	}
+	return strings.Join(hits, "\n")
+}
@@ -0,0 +1,136 @@
+// Command yubikey-onboard provisions and verifies a developer's YubiKey
+// for GPG-backed SSH and commit signing.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+// commands is populated by the files implementing each subcommand.
+var commands []*command
+
+func register(c *command) {
+	commands = append(commands, c)
+}
+
+// dryRun previews instead of acting: file writes, privileged commands,
+// installers and doctor fixes are described, prompts resolve to their
+// defaults, and nothing on the machine changes. Read-only probes still
+// run so the preview reflects this machine.
+var dryRun bool
+
+func main() {
+	flag.BoolVar(&asciiFlag, "ascii", false, "use plain ASCII output (no unicode glyphs)")
+	flag.BoolVar(&dryRun, "dry-run", false, "describe writes, privileged commands and fixes instead of doing them")
+	flag.StringVar(&userConfigPath, "config", "", "read defaults from this JSON file instead of the config dir")
+	flag.DurationVar(&waitForCard, "wait-for-card", 0, "poll this long for the card instead of prompting to insert it")
+	flag.BoolVar(&noCardWait, "no-card-wait", false, "never wait for the card (for flows that run without the hardware)")
+	transcriptPath := flag.String("transcript", "", "append prompts, answers and commands to this file (secrets redacted)")
+	flag.BoolVar(&ephemeralMode, "ephemeral", false, "use a temporary GNUPGHOME and skip persistent changes")
+	flag.StringVar(&outputFormat, "format", "pretty", "output format: pretty, plain, json, tap or quiet")
+	flag.DurationVar(&promptTimeout, "prompt-timeout", 0, "give up on unanswered prompts after this long (0 waits forever)")
+	flag.StringVar(&promptTimeoutAction, "on-prompt-timeout", "abort", "what a timed-out prompt does: abort, skip or retry")
+	flag.StringVar(&sessionPath, "record", "", "record external commands and their output to this file (secrets redacted)")
+	replayPath := flag.String("replay", "", "serve external command results from a recorded session instead of executing")
+	flag.Usage = usage
+	flag.Parse()
+	// Everything below returns errors; main is the only place that may
+	// decide to exit, so the tool stays embeddable and testable.
+	if err := setupGlobalFlags(*transcriptPath, *replayPath); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", brandProduct, err)
+		os.Exit(1)
+	}
+	flush := installRedaction()
+	code := run()
+	saveSession()
+	flush()
+	os.Exit(code)
+}
+
+// setupGlobalFlags applies the side-effectful global flags before any
+// command runs.
+func setupGlobalFlags(transcriptPath, replayPath string) error {
+	if transcriptPath != "" {
+		path, err := resolveOutputPath(transcriptPath)
+		if err == nil {
+			err = openTranscript(path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if ephemeralMode {
+		if err := enterEphemeralMode(); err != nil {
+			return err
+		}
+	}
+	if replayPath != "" {
+		path, err := resolveExistingFile(replayPath)
+		if err == nil {
+			err = loadSessionReplay(path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if sessionPath != "" {
+		path, err := resolveOutputPath(sessionPath)
+		if err != nil {
+			return err
+		}
+		sessionPath = path
+	}
+	return nil
+}
+
+func run() int {
+	if flag.NArg() < 1 {
+		usage()
+		return 2
+	}
+	name := flag.Arg(0)
+	for _, c := range commands {
+		if c.name == name {
+			if err := runCommand(c, flag.Args()[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s: %v\n", brandProduct, name, err)
+				return 1
+			}
+			return 0
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%s: unknown command %q\n", brandProduct, name)
+	usage()
+	return 2
+}
+
+// runCommand runs one command, turning a prompt-timeout abort into an
+// ordinary error so the redaction flush and exit code still happen.
+func runCommand(c *command, args []string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(exitPrompt); ok {
+				err = fmt.Errorf("aborted: a prompt went unanswered for %s", promptTimeout)
+				return
+			}
+			panic(r)
+		}
+	}()
+	return c.run(args)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [options]\n", brandProduct)
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", c.name, c.summary)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+func init() {
+	registerStep(&step{
+		name:      "mtls",
+		desc:      "Enroll a client certificate (mTLS) from the PIV authentication slot",
+		optional:  true,
+		exclusive: true,
+		run:       mtlsStep,
+	})
+}
+
+// pivAuthSlot is the PIV slot used for client authentication.
+const pivAuthSlot = "9a"
+
+func mtlsStep(ctx *setupContext) error {
+	if !haveExec("ykman") {
+		return fmt.Errorf("ykman not installed; it is required for PIV operations")
+	}
+	id, err := ctx.identity()
+	if err != nil {
+		return err
+	}
+	caURL := org().CAURL
+	if caURL == "" {
+		caURL = promptString("Internal CA enrollment endpoint?", "")
+	}
+	if caURL == "" {
+		return fmt.Errorf("no CA endpoint configured (set ca_url in org.json or YKO_CA_URL)")
+	}
+
+	work, err := os.MkdirTemp("", "yko-mtls-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(work)
+
+	pubPath := filepath.Join(work, "piv.pub")
+	csrPath := filepath.Join(work, "request.csr")
+
+	// Generate a fresh keypair in the slot unless one is already there.
+	if _, err := output("ykman", "piv", "keys", "export", pivAuthSlot, pubPath); err != nil {
+		fmt.Println("  generating a new key in slot " + pivAuthSlot)
+		if _, err := output("ykman", "piv", "keys", "generate", pivAuthSlot, pubPath); err != nil {
+			return err
+		}
+	}
+	subject := fmt.Sprintf("CN=%s", id.email)
+	if _, err := output("ykman", "piv", "certificates", "request",
+		"-s", subject, pivAuthSlot, pubPath, csrPath); err != nil {
+		return err
+	}
+
+	cert, err := submitCSR(caURL, csrPath)
+	if err != nil {
+		return err
+	}
+	certPath := filepath.Join(work, "issued.crt")
+	if err := os.WriteFile(certPath, cert, 0o600); err != nil {
+		return err
+	}
+	if _, err := output("ykman", "piv", "certificates", "import", pivAuthSlot, certPath); err != nil {
+		return err
+	}
+	fmt.Println("  certificate loaded into slot " + pivAuthSlot)
+	return verifyCertVisibility(id)
+}
+
+func submitCSR(caURL, csrPath string) ([]byte, error) {
+	csr, err := os.Open(csrPath)
+	if err != nil {
+		return nil, err
+	}
+	defer csr.Close()
+	resp, err := http.Post(caURL, "application/pkcs10", csr)
+	if err != nil {
+		return nil, fmt.Errorf("CA enrollment: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CA enrollment: HTTP %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if !strings.Contains(string(body), "BEGIN CERTIFICATE") {
+		return nil, fmt.Errorf("CA returned no PEM certificate")
+	}
+	return body, nil
+}
+
+// verifyCertVisibility checks that the browser stack can see the card's
+// certificate: NSS via p11-kit on Linux, the keychain on macOS.
+func verifyCertVisibility(id gpgIdentity) error {
+	switch runtime.GOOS {
+	case "darwin":
+		out, err := output("security", "list-smartcards")
+		if err != nil || out == "" {
+			fmt.Println("  warning: macOS does not see the smart card; check CryptoTokenKit")
+			return nil
+		}
+	default:
+		home, _ := os.UserHomeDir()
+		db := "sql:" + filepath.Join(home, ".pki", "nssdb")
+		if !haveExec("certutil") {
+			fmt.Println("  certutil not installed; skipping NSS check")
+			return nil
+		}
+		out, err := output("certutil", "-d", db, "-L")
+		if err != nil || !strings.Contains(out, id.email) {
+			fmt.Println("  warning: NSS database does not list the certificate; browsers may need the PKCS#11 module registered (modutil)")
+			return nil
+		}
+	}
+	fmt.Println("  certificate is visible to the system certificate store")
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+func init() {
+	register(&command{
+		name:    "offboard",
+		summary: "undo this tool's configuration when someone leaves",
+		run:     runOffboard,
+	})
+}
+
+// runOffboard reverses the setup flow: signing config, managed files
+// and caches go away locally, the forge keys are revoked via the API,
+// and the card can optionally be factory-reset for reuse. This is the
+// flow IT clicks through by hand today, in the same order.
+func runOffboard(args []string) error {
+	fs := flag.NewFlagSet("offboard", flag.ContinueOnError)
+	resetCard := fs.Bool("reset-card", false, "factory-reset the OpenPGP applet when done")
+	keepForge := fs.Bool("keep-forge-keys", false, "leave the Gitea keys in place")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if !confirm("This removes signing configuration and revokes forge keys. Continue?", false) {
+		return fmt.Errorf("offboard aborted")
+	}
+
+	fmt.Println("Removing git signing configuration")
+	output("git", "config", "--global", "--unset", "user.signingkey")
+	output("git", "config", "--global", "--unset", "commit.gpgsign")
+	output("git", "config", "--global", "--unset", "tag.gpgsign")
+	if inc, err := gitIncludePath(); err == nil {
+		output("git", "config", "--global", "--unset", "include.path", "^"+regexp.QuoteMeta(inc)+"$")
+	}
+
+	fmt.Println("Removing managed files")
+	home, _ := os.UserHomeDir()
+	for _, path := range managedFiles(home) {
+		if err := os.Remove(path); err == nil {
+			fmt.Println("  removed " + path)
+		}
+	}
+
+	fmt.Println("Clearing caches and state")
+	output("gpg-connect-agent", "reloadagent", "/bye")
+	if path, err := statePath(); err == nil {
+		os.Remove(path)
+	}
+	if dir, err := configDir(); err == nil {
+		os.Remove(filepath.Join(dir, "org.json"))
+	}
+
+	if !*keepForge {
+		if err := revokeForgeKeys(); err != nil {
+			fmt.Println(sym().fail + " " + err.Error())
+			fmt.Println("  revoke the keys manually at " + giteaURL() + "/user/settings/keys")
+		}
+	}
+
+	if *resetCard {
+		if !confirm("Factory-reset the card? This destroys its keys irreversibly.", false) {
+			fmt.Println("Card left untouched")
+		} else if _, err := output("ykman", "openpgp", "reset", "--force"); err != nil {
+			return fmt.Errorf("card reset failed: %w", err)
+		} else {
+			fmt.Println("Card reset to factory state")
+		}
+	}
+
+	auditLog("offboard completed")
+	fmt.Println("Offboarding done")
+	return nil
+}
+
+// managedFiles lists everything the setup steps wrote outside git
+// config. Paths that were never created are skipped silently.
+func managedFiles(home string) []string {
+	return []string{
+		filepath.Join(home, ".config", "environment.d", "90-yubikey-onboard.conf"),
+		filepath.Join(home, ".config", "git", brandProduct+".inc"),
+		filepath.Join(gpgDirs().Home, "gpg-agent.conf"),
+		filepath.Join(home, "gpg-public-key.asc"),
+	}
+}
+
+// revokeForgeKeys deletes the user's SSH and GPG keys from Gitea. Needs
+// a token with admin or write:user scope, the same one auth login
+// stores.
+func revokeForgeKeys() error {
+	var sshKeys []struct {
+		ID int64 `json:"id"`
+	}
+	if err := giteaGet("/api/v1/user/keys", &sshKeys); err != nil {
+		return err
+	}
+	for _, k := range sshKeys {
+		if err := giteaDelete(fmt.Sprintf("/api/v1/user/keys/%d", k.ID)); err != nil {
+			return err
+		}
+	}
+	var gpgKeys []struct {
+		ID int64 `json:"id"`
+	}
+	if err := giteaGet("/api/v1/user/gpg_keys", &gpgKeys); err != nil {
+		return err
+	}
+	for _, k := range gpgKeys {
+		if err := giteaDelete(fmt.Sprintf("/api/v1/user/gpg_keys/%d", k.ID)); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Revoked %d SSH and %d GPG key(s) on %s\n", len(sshKeys), len(gpgKeys), giteaURL())
+	return nil
+}
+
+func giteaDelete(path string) error {
+	token, err := giteaToken()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("DELETE", giteaURL()+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("gitea: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("gitea: DELETE %s: HTTP %s", path, resp.Status)
+	}
+	// Drain so the connection can be reused across the loop.
+	json.NewDecoder(resp.Body).Decode(&struct{}{})
+	return nil
+}
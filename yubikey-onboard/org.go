@@ -0,0 +1,103 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Branding defaults. Overridable at build time, e.g.
+//
+//	go build -ldflags "-X main.brandProduct=acme-key-setup -X 'main.brandBanner=ACME key onboarding'"
+//
+// so customers can ship a rebranded binary without forking.
+var (
+	brandProduct = "yubikey-onboard"
+	brandBanner  = ""
+)
+
+// orgConfig holds everything that is specific to one organization, so
+// other companies can adopt the tool by shipping their own org.json.
+type orgConfig struct {
+	Name        string `json:"name"`
+	Banner      string `json:"banner"`
+	Contact     string `json:"contact"`
+	WikiURL     string `json:"wiki_url"`
+	GiteaURL    string `json:"gitea_url"`
+	PassRepoURL string `json:"pass_repo_url"`
+	CAURL       string `json:"ca_url"`
+	// PINEscrowBackend selects where admin PINs are escrowed during
+	// admin-run provisioning: "pass", "vault" or empty to disable.
+	PINEscrowBackend string `json:"pin_escrow_backend"`
+	// CosignURL/CosignSHA256 pin the cosign binary offered when it is
+	// not already installed; downloads are refused without the pin.
+	CosignURL    string `json:"cosign_url"`
+	CosignSHA256 string `json:"cosign_sha256"`
+	VaultAddr    string `json:"vault_addr"`
+	VaultKVMount string `json:"vault_kv_mount"`
+	EscrowURL    string `json:"escrow_url"`
+	Bastion      string `json:"bastion"`
+	// RevocationListURL serves a clearsigned list of fingerprints whose
+	// owners have left; team sync deletes those keys locally.
+	RevocationListURL string `json:"revocation_list_url"`
+	// RevocationSignerFingerprint is the only key allowed to sign that
+	// list. Any keyring key would otherwise do - including colleague
+	// keys just imported from WKD - and deleting keys on a colleague's
+	// say-so is not the deal.
+	RevocationSignerFingerprint string `json:"revocation_signer_fingerprint"`
+	// InventoryURL is the asset-inventory API used to map card serials
+	// to owners; empty disables registration.
+	InventoryURL string `json:"inventory_url"`
+	// AptProxy and BrewMirror point package downloads at the office
+	// mirror instead of throttled upstreams.
+	AptProxy   string `json:"apt_proxy"`
+	BrewMirror string `json:"brew_mirror"`
+}
+
+//go:embed org.json
+var defaultOrgJSON []byte
+
+var (
+	orgOnce sync.Once
+	orgCfg  orgConfig
+)
+
+// org returns the active organization config. Precedence, lowest to
+// highest: the embedded org.json, an org.json in the user config dir,
+// then YKO_* environment variables for individual fields.
+func org() orgConfig {
+	orgOnce.Do(func() {
+		if err := json.Unmarshal(defaultOrgJSON, &orgCfg); err != nil {
+			panic(fmt.Sprintf("embedded org.json is invalid: %v", err))
+		}
+		if dir, err := configDir(); err == nil {
+			if data, err := os.ReadFile(filepath.Join(dir, "org.json")); err == nil {
+				if err := json.Unmarshal(data, &orgCfg); err != nil {
+					fmt.Fprintf(os.Stderr, "yubikey-onboard: ignoring invalid org.json override: %v\n", err)
+				}
+			}
+		}
+		overrideFromEnv("YKO_GITEA_URL", &orgCfg.GiteaURL)
+		overrideFromEnv("YKO_CA_URL", &orgCfg.CAURL)
+		overrideFromEnv("YKO_ESCROW_URL", &orgCfg.EscrowURL)
+		overrideFromEnv("YKO_BASTION", &orgCfg.Bastion)
+		overrideFromEnv("YKO_WIKI_URL", &orgCfg.WikiURL)
+		overrideFromEnv("YKO_APT_PROXY", &orgCfg.AptProxy)
+		overrideFromEnv("YKO_BREW_MIRROR", &orgCfg.BrewMirror)
+		if orgCfg.Banner == "" && brandBanner != "" {
+			orgCfg.Banner = brandBanner
+		}
+		orgCfg.GiteaURL = strings.TrimRight(orgCfg.GiteaURL, "/")
+	})
+	return orgCfg
+}
+
+func overrideFromEnv(env string, dst *string) {
+	if v := os.Getenv(env); v != "" {
+		*dst = v
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Running the tool under sudo must not leave root-owned files in the
+// user's home; gpg refuses to work with them later.
+
+// invokerIDs returns the real user's uid/gid when running under sudo.
+func invokerIDs() (uid, gid int, ok bool) {
+	if os.Geteuid() != 0 {
+		return 0, 0, false
+	}
+	uid, err1 := strconv.Atoi(os.Getenv("SUDO_UID"))
+	gid, err2 := strconv.Atoi(os.Getenv("SUDO_GID"))
+	if err1 != nil || err2 != nil || uid == 0 {
+		return 0, 0, false
+	}
+	return uid, gid, true
+}
+
+// fixOwnership hands a file just created by root back to the invoking
+// user. A no-op when not running under sudo.
+func fixOwnership(path string) {
+	if uid, gid, ok := invokerIDs(); ok {
+		os.Chown(path, uid, gid)
+	}
+}
+
+// checkOwnership flags files in the gpg and tool directories that the
+// current user cannot own - the classic aftermath of a sudo-run setup.
+func checkOwnership() error {
+	me := os.Getuid()
+	if me == 0 {
+		return nil // root owns everything by definition
+	}
+	dirs := []string{gpgDirs().Home}
+	if dir, err := configDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	var foreign []string
+	for _, dir := range dirs {
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if uid, ok := fileOwnerUID(info); ok && uid != me {
+				foreign = append(foreign, path)
+			}
+			return nil
+		})
+	}
+	if len(foreign) > 0 {
+		return fmt.Errorf("%d file(s) not owned by you (first: %s); fix with: sudo chown -R $USER %s",
+			len(foreign), foreign[0], gpgDirs().Home)
+	}
+	return nil
+}
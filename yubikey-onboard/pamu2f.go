@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+func init() {
+	registerStep(&step{
+		name:      "pam-u2f",
+		desc:      "Require a YubiKey touch for sudo (pam-u2f)",
+		optional:  true,
+		exclusive: true,
+		run:       pamU2FStep,
+	})
+}
+
+const pamSudoFile = "/etc/pam.d/sudo"
+
+func pamU2FStep(ctx *setupContext) error {
+	if skipPersistent("pam-u2f configuration") {
+		return nil
+	}
+	if runtime.GOOS != "linux" {
+		fmt.Println("  pam-u2f is Linux-only; skipping")
+		return nil
+	}
+	if !haveExec("pamu2fcfg") {
+		if err := installPackages("libpam-u2f", "pamu2fcfg"); err != nil {
+			return err
+		}
+	}
+
+	// Register the key. pamu2fcfg blocks until the key is touched.
+	home, _ := os.UserHomeDir()
+	keysPath := filepath.Join(home, ".config", "Yubico", "u2f_keys")
+	if err := os.MkdirAll(filepath.Dir(keysPath), 0o700); err != nil {
+		return err
+	}
+	fmt.Println("  touch the YubiKey when it blinks...")
+	line, err := output("pamu2fcfg")
+	if err != nil {
+		return err
+	}
+	if err := appendU2FKey(keysPath, line); err != nil {
+		return err
+	}
+	fmt.Printf("  registered key in %s\n", keysPath)
+
+	fmt.Println()
+	fmt.Println("  Enabling pam-u2f for sudo can lock you out if the key is lost.")
+	fmt.Println("  A backup of " + pamSudoFile + " will be kept, and you should keep a")
+	fmt.Println("  root shell open in another terminal until you have verified sudo works.")
+	if !confirm("Enable pam-u2f for sudo now?", false) {
+		fmt.Println("  skipped enabling; your key is registered and you can enable it later")
+		return nil
+	}
+	return enablePamU2FSudo()
+}
+
+func appendU2FKey(path, line string) error {
+	existing, _ := os.ReadFile(path)
+	if strings.Contains(string(existing), line) {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(line + "\n")
+	return err
+}
+
+func enablePamU2FSudo() error {
+	current, err := os.ReadFile(pamSudoFile)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(string(current), "pam_u2f.so") {
+		fmt.Println("  " + pamSudoFile + " already references pam_u2f")
+		return nil
+	}
+	backup := fmt.Sprintf("%s.bak-%s", pamSudoFile, time.Now().Format("20060102-150405"))
+	updated := "auth sufficient pam_u2f.so cue\n" + string(current)
+
+	script := fmt.Sprintf("cp %s %s && printf '%%s' %q > %s", pamSudoFile, backup, updated, pamSudoFile)
+	if err := runPrivileged("enable pam_u2f in "+pamSudoFile, "sh", "-c", script); err != nil {
+		return err
+	}
+	fmt.Printf("  enabled; rollback with: sudo cp %s %s\n", backup, pamSudoFile)
+	fmt.Println("  now verify in a NEW terminal that `sudo true` works before closing this one")
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// resolvePath turns any user-supplied path into a clean absolute one:
+// ~ and ~user expand, $VAR and ${VAR} expand, relative paths resolve
+// against the working directory. Spaces need no special treatment -
+// the shell already de-quoted the argument; this must not split on
+// them. Every path taken from a flag or prompt goes through here.
+func resolvePath(input string) (string, error) {
+	p := strings.TrimSpace(input)
+	if p == "" {
+		return "", fmt.Errorf("empty path")
+	}
+	p = os.ExpandEnv(p)
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		p = filepath.Join(home, strings.TrimPrefix(p, "~"))
+	} else if strings.HasPrefix(p, "~") {
+		// ~user form: look the account up instead of guessing /home.
+		name, rest, _ := strings.Cut(p[1:], "/")
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", fmt.Errorf("cannot expand ~%s: %v", name, err)
+		}
+		p = filepath.Join(u.HomeDir, rest)
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(abs), nil
+}
+
+// resolveExistingFile resolves a path and insists it names a regular
+// file, giving path-typo errors at the prompt rather than deep inside
+// a step.
+func resolveExistingFile(input string) (string, error) {
+	p, err := resolvePath(input)
+	if err != nil {
+		return "", err
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		return "", fmt.Errorf("%s does not exist", p)
+	}
+	if fi.IsDir() {
+		return "", fmt.Errorf("%s is a directory, expected a file", p)
+	}
+	return p, nil
+}
+
+// resolveOutputPath resolves a path meant to be written and checks its
+// parent directory exists.
+func resolveOutputPath(input string) (string, error) {
+	p, err := resolvePath(input)
+	if err != nil {
+		return "", err
+	}
+	if fi, err := os.Stat(filepath.Dir(p)); err != nil || !fi.IsDir() {
+		return "", fmt.Errorf("directory %s does not exist", filepath.Dir(p))
+	}
+	return p, nil
+}
+
+// configDir returns the tool's own config directory, creating it on
+// first use.
+func configDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "yubikey-onboard")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	fixOwnership(dir)
+	return dir, nil
+}
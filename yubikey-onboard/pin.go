@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"yubikey-onboard/internal/parse"
+)
+
+func init() {
+	registerStep(&step{
+		name:      "pin",
+		desc:      "Replace the factory default PINs on a brand-new key",
+		optional:  false,
+		exclusive: true,
+		explain: "Every YubiKey ships with the same factory PINs (123456/12345678); " +
+			"anyone who picks up the key could use it until they are changed. " +
+			"This step sets a personal user PIN and a separate admin PIN.",
+		run: pinStep,
+	})
+}
+
+// Factory defaults for OpenPGP cards.
+const (
+	defaultPIN      = "123456"
+	defaultAdminPIN = "12345678"
+)
+
+// pinRetryCounters reads the remaining attempts for the user PIN,
+// reset code and admin PIN from the card.
+func pinRetryCounters() (user, reset, admin int, err error) {
+	out, err := output("gpg", "--card-status")
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if cs := parse.CardStatusOutput(out); cs.HaveRetries {
+		return cs.Retries[0], cs.Retries[1], cs.Retries[2], nil
+	}
+	return 0, 0, 0, fmt.Errorf("gpg --card-status did not report PIN retry counters")
+}
+
+// ensurePINRetriesSafe refuses PIN operations when a single failed
+// attempt would block the PIN; that is a human-with-the-unblock-flow
+// situation, not something to automate through.
+func ensurePINRetriesSafe() error {
+	user, _, admin, err := pinRetryCounters()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  PIN retries remaining: user %d, admin %d\n", user, admin)
+	if user <= 1 {
+		return fmt.Errorf("only %d user PIN attempt left; unblock it first with the admin PIN (gpg --card-edit, then passwd > unblock)", user)
+	}
+	if admin <= 1 {
+		return fmt.Errorf("only %d admin PIN attempt left; STOP - a wrong admin PIN now means a factory reset. Ask for help", admin)
+	}
+	return nil
+}
+
+func pinStep(ctx *setupContext) error {
+	if err := ensurePINRetriesSafe(); err != nil {
+		return err
+	}
+	if !confirm("Is this a brand-new key still using the factory default PINs?", false) {
+		if !confirm("Change the user PIN anyway?", false) {
+			fmt.Println("  keeping existing PINs")
+			return nil
+		}
+		return changeUserPIN()
+	}
+	if !haveExec("ykman") {
+		fmt.Println("  ykman not installed; run `gpg --change-pin` yourself")
+		fmt.Printf("  (factory defaults: PIN %s, admin PIN %s)\n", defaultPIN, defaultAdminPIN)
+		return nil
+	}
+
+	// Admin-run provisioning may have staged non-factory initial PINs
+	// in Vault; use those as the current PINs when present.
+	currentPIN, currentAdmin := defaultPIN, defaultAdminPIN
+	if org().VaultAddr != "" {
+		if id, err := ctx.identity(); err == nil {
+			if bundle, err := vaultProvisioning(id.email); err == nil && bundle != nil && bundle.InitialPIN != "" {
+				fmt.Println("  using pre-staged initial PINs from Vault")
+				currentPIN, currentAdmin = bundle.InitialPIN, bundle.InitialAdminPIN
+			}
+		}
+	}
+
+	newPIN, err := promptNewPIN(pinKindUser)
+	if err != nil {
+		return err
+	}
+	newAdmin, err := promptNewPIN(pinKindAdmin)
+	if err != nil {
+		return err
+	}
+
+	if err := ykmanChangePIN("change-pin", "--pin", currentPIN, "--new-pin", newPIN); err != nil {
+		return err
+	}
+	fmt.Println("  user PIN changed")
+	if err := ykmanChangePIN("change-admin-pin", "--admin-pin", currentAdmin, "--new-admin-pin", newAdmin); err != nil {
+		return err
+	}
+	fmt.Println("  admin PIN changed")
+	verifyPINLoopback(newPIN)
+	fmt.Println("  store both PINs in your password manager NOW; a forgotten admin PIN means a factory reset")
+	if confirm("Is this an admin-run provisioning (escrow the admin PIN per policy)?", false) {
+		id, err := ctx.identity()
+		if err != nil {
+			return err
+		}
+		if err := escrowAdminPIN(id, newAdmin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changeUserPIN rotates the user PIN on a key already in service. Both
+// PINs come in through the tool's own masked prompts, so the flow works
+// the same over ssh as on a desktop.
+func changeUserPIN() error {
+	if !haveExec("ykman") {
+		fmt.Println("  ykman not installed; run `gpg --change-pin` yourself")
+		return nil
+	}
+	current, err := promptCurrentPIN(pinKindUser)
+	if err != nil {
+		return err
+	}
+	newPIN, err := promptNewPIN(pinKindUser)
+	if err != nil {
+		return err
+	}
+	if err := ykmanChangePIN("change-pin", "--pin", current, "--new-pin", newPIN); err != nil {
+		return err
+	}
+	fmt.Println("  user PIN changed")
+	verifyPINLoopback(newPIN)
+	return nil
+}
+
+// verifyPINLoopback proves the PIN actually works with a loopback test
+// signature - no pinentry popup involved. Best effort: a card that has
+// no signing key yet simply cannot be probed.
+func verifyPINLoopback(pin string) {
+	if err := loopbackSignProbe(pin); err != nil {
+		fmt.Printf("  %s could not verify the PIN with a test signature (%v)\n", sym().warn, err)
+		return
+	}
+	fmt.Printf("  %s PIN verified with a test signature\n", sym().ok)
+}
+
+func ykmanChangePIN(sub string, args ...string) error {
+	cmd := exec.Command("ykman", append([]string{"openpgp", "access", sub}, args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ykman openpgp access %s: %w", sub, err)
+	}
+	return nil
+}
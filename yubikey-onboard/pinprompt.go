@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pinKind carries what each PIN type requires, so prompts and policy
+// checks stay in one place instead of every caller hardcoding "6".
+type pinKind struct {
+	label  string
+	minLen int
+}
+
+var (
+	pinKindUser  = pinKind{"user PIN", 6}
+	pinKindAdmin = pinKind{"admin PIN", 8}
+	pinKindReset = pinKind{"reset code", 8}
+)
+
+// promptNewPIN asks for a fresh PIN of the given kind: masked entry,
+// policy validation, and a repeat to catch typos. Used wherever the
+// tool sets a PIN itself; pinentry popups in a terminal session confuse
+// people, and this keeps the wording consistent.
+func promptNewPIN(kind pinKind) (string, error) {
+	for {
+		pin, err := readSecret(fmt.Sprintf("  new %s (min %d digits):", kind.label, kind.minLen))
+		if err != nil {
+			return "", err
+		}
+		if err := validatePIN(pin, kind); err != nil {
+			fmt.Println("  " + err.Error())
+			continue
+		}
+		again, err := readSecret(fmt.Sprintf("  repeat %s:", kind.label))
+		if err != nil {
+			return "", err
+		}
+		if pin != again {
+			fmt.Println("  PINs do not match, try again")
+			continue
+		}
+		return pin, nil
+	}
+}
+
+// promptCurrentPIN asks for an existing PIN: masked, length-checked,
+// but no policy judgment - it is whatever is already on the card.
+func promptCurrentPIN(kind pinKind) (string, error) {
+	for {
+		pin, err := readSecret(fmt.Sprintf("  current %s:", kind.label))
+		if err != nil {
+			return "", err
+		}
+		if len(pin) < kind.minLen {
+			fmt.Printf("  a %s has at least %d characters, try again\n", kind.label, kind.minLen)
+			continue
+		}
+		return pin, nil
+	}
+}
+
+// validatePIN rejects the weak choices people reach for first.
+func validatePIN(pin string, kind pinKind) error {
+	if len(pin) < kind.minLen {
+		return fmt.Errorf("too short: need at least %d characters", kind.minLen)
+	}
+	if pin == defaultPIN || pin == defaultAdminPIN {
+		return fmt.Errorf("that is the factory default; pick something else")
+	}
+	if strings.Count(pin, pin[:1]) == len(pin) {
+		return fmt.Errorf("all characters are the same; pick something less guessable")
+	}
+	if strings.Contains("0123456789", pin) || strings.Contains("9876543210", pin) {
+		return fmt.Errorf("sequential digits are too guessable")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+)
+
+func init() {
+	register(&command{
+		name:    "pkcs11",
+		summary: "locate or install a PKCS#11 module and smoke-test it",
+		run:     runPKCS11,
+	})
+}
+
+func runPKCS11(args []string) error {
+	fs := flag.NewFlagSet("pkcs11", flag.ContinueOnError)
+	install := fs.Bool("install", false, "install ykcs11/OpenSC if no module is present")
+	skipTest := fs.Bool("skip-test", false, "do not run the signing smoke test")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	module, err := findPKCS11Module()
+	if err != nil && *install {
+		if ierr := installPackages("ykcs11", "opensc"); ierr != nil {
+			return ierr
+		}
+		module, err = findPKCS11Module()
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("PKCS#11 module: %s\n", module)
+
+	if err := saveModulePath(module); err != nil {
+		return err
+	}
+	if *skipTest {
+		return nil
+	}
+	return pkcs11SmokeTest(module)
+}
+
+// saveModulePath records the module location so other steps (cosign,
+// mTLS enrollment) agree on which library to load.
+func saveModulePath(module string) error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "pkcs11-module")
+	if err := writeFileAtomic(path, []byte(module+"\n"), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("recorded module path in %s\n", path)
+	return nil
+}
+
+// pkcs11SmokeTest asks pkcs11-tool to list the card's objects through
+// the module, which exercises the full library/reader/card path without
+// needing the PIN.
+func pkcs11SmokeTest(module string) error {
+	if !haveExec("pkcs11-tool") {
+		fmt.Println("pkcs11-tool not installed; skipping smoke test")
+		return nil
+	}
+	out, err := output("pkcs11-tool", "--module", module, "--list-objects", "--type", "pubkey")
+	if err != nil {
+		return fmt.Errorf("smoke test failed (is the key plugged in?): %w", err)
+	}
+	fmt.Println("smoke test OK:")
+	fmt.Println(out)
+	return nil
+}
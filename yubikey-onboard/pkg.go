@@ -0,0 +1,313 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// installTailLines is how much installer output a failure report keeps.
+const installTailLines = 15
+
+// pkgManager describes one system package manager. Steps name packages
+// in Debian terms; rename maps those onto this distro's spelling, so
+// call sites stay manager-agnostic.
+type pkgManager struct {
+	name       string
+	privileged bool
+	argv       func(pkgs []string) []string
+	rename     map[string]string
+	prepare    func()                    // environment setup before the first install
+	after      func(pkgs []string) error // post-install wiring some distros need
+}
+
+var aptManager = pkgManager{
+	name:       "apt-get",
+	privileged: true,
+	argv: func(pkgs []string) []string {
+		argv := []string{"apt-get", "install", "-y", "-o", "Acquire::Retries=3"}
+		if p := org().AptProxy; p != "" {
+			argv = append(argv, "-o", "Acquire::http::Proxy="+p, "-o", "Acquire::https::Proxy="+p)
+		}
+		return append(argv, pkgs...)
+	},
+}
+
+var brewManager = pkgManager{
+	name: "brew",
+	argv: func(pkgs []string) []string {
+		return append([]string{"brew", "install"}, pkgs...)
+	},
+	prepare: func() {
+		if m := org().BrewMirror; m != "" {
+			// Homebrew takes mirrors via the environment.
+			os.Setenv("HOMEBREW_BOTTLE_DOMAIN", m)
+			os.Setenv("HOMEBREW_API_DOMAIN", m+"/api")
+		}
+	},
+}
+
+// dnfRenames covers Fedora/RHEL, where the gpg stack is split and
+// named differently from Debian.
+var dnfRenames = map[string]string{
+	"gnupg":           "gnupg2",
+	"pinentry-curses": "pinentry",
+	"pcscd":           "pcsc-lite",
+	"ccid":            "pcsc-lite-ccid",
+	"libpam-u2f":      "pam-u2f",
+	"pamu2fcfg":       "pam-u2f",
+	"ykcs11":          "yubico-piv-tool",
+}
+
+func dnfLike(tool string) pkgManager {
+	return pkgManager{
+		name:       tool,
+		privileged: true,
+		argv: func(pkgs []string) []string {
+			return append([]string{tool, "install", "-y"}, pkgs...)
+		},
+		rename: dnfRenames,
+	}
+}
+
+var pacmanManager = pkgManager{
+	name:       "pacman",
+	privileged: true,
+	argv: func(pkgs []string) []string {
+		return append([]string{"pacman", "-S", "--noconfirm", "--needed"}, pkgs...)
+	},
+	rename: map[string]string{
+		"pcscd":           "pcsclite",
+		"pinentry-curses": "pinentry",
+		"libpam-u2f":      "pam-u2f",
+		"pamu2fcfg":       "pam-u2f",
+		"ykcs11":          "yubico-piv-tool",
+	},
+	after: pacmanPostInstall,
+}
+
+// pacmanPostInstall enables the pcscd socket after installing the card
+// stack; unlike apt, pacman never starts services, and scdaemon finds
+// no reader until the socket is live.
+func pacmanPostInstall(pkgs []string) error {
+	for _, p := range pkgs {
+		if p == "pcsclite" || p == "ccid" || p == "gnupg" {
+			return enablePcscd()
+		}
+	}
+	return nil
+}
+
+// apkManager covers Alpine, common in container dev shells. Like
+// pacman, apk installs services without starting them.
+var apkManager = pkgManager{
+	name:       "apk",
+	privileged: true,
+	argv: func(pkgs []string) []string {
+		return append([]string{"apk", "add"}, pkgs...)
+	},
+	rename: map[string]string{
+		"pinentry-curses": "pinentry",
+		"pcscd":           "pcsc-lite",
+		"libpam-u2f":      "pam-u2f",
+		"pamu2fcfg":       "pam-u2f",
+		"ykcs11":          "yubico-piv-tool",
+	},
+	after: apkPostInstall,
+}
+
+func apkPostInstall(pkgs []string) error {
+	for _, p := range pkgs {
+		if p == "pcsc-lite" || p == "ccid" || p == "gnupg" {
+			return enablePcscd()
+		}
+	}
+	return nil
+}
+
+// enablePcscd starts pcscd under whichever service manager this box
+// runs. Alpine ships OpenRC; everything else the installer supports is
+// systemd.
+func enablePcscd() error {
+	if haveExec("rc-service") {
+		if err := runPrivileged("enable pcscd service", "rc-update", "add", "pcscd"); err != nil {
+			return err
+		}
+		return runPrivileged("start pcscd service", "rc-service", "pcscd", "start")
+	}
+	return runPrivileged("enable pcscd socket", "systemctl", "enable", "--now", "pcscd.socket")
+}
+
+// zypperManager covers openSUSE Tumbleweed and Leap. SUSE follows the
+// Fedora split of the gpg stack, with its own spelling for pam-u2f.
+var zypperManager = pkgManager{
+	name:       "zypper",
+	privileged: true,
+	argv: func(pkgs []string) []string {
+		return append([]string{"zypper", "--non-interactive", "install"}, pkgs...)
+	},
+	rename: map[string]string{
+		"gnupg":           "gpg2",
+		"pinentry-curses": "pinentry",
+		"pcscd":           "pcsc-lite",
+		"ccid":            "pcsc-ccid",
+		"libpam-u2f":      "pam_u2f",
+		"pamu2fcfg":       "pam_u2f",
+		"ykcs11":          "yubico-piv-tool",
+	},
+}
+
+// detectPkgManager picks the system's package manager, or nil when
+// none is recognized.
+func detectPkgManager() *pkgManager {
+	switch {
+	case runtime.GOOS == "darwin" && haveExec("brew"):
+		return &brewManager
+	case haveExec("apt-get"):
+		return &aptManager
+	case haveExec("dnf"):
+		m := dnfLike("dnf")
+		return &m
+	case haveExec("yum"):
+		m := dnfLike("yum")
+		return &m
+	case haveExec("pacman"):
+		return &pacmanManager
+	case haveExec("zypper"):
+		return &zypperManager
+	case haveExec("apk"):
+		return &apkManager
+	}
+	return nil
+}
+
+// translate maps Debian-flavored package names to this manager's,
+// dropping duplicates that renaming can introduce.
+func (m *pkgManager) translate(pkgs []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, p := range pkgs {
+		if r, ok := m.rename[p]; ok {
+			p = r
+		}
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// installPackages installs the named packages with the system package
+// manager, prompting before touching anything.
+func installPackages(pkgs ...string) error {
+	if len(pkgs) == 0 {
+		return nil
+	}
+	m := detectPkgManager()
+	if m == nil {
+		return fmt.Errorf("no supported package manager found; install manually: %v", pkgs)
+	}
+	pkgs = m.translate(pkgs)
+	if !(m.privileged && printSudoScript) && !confirm(fmt.Sprintf("Install %v via %s?", pkgs, m.name), true) {
+		return fmt.Errorf("installation of %v declined", pkgs)
+	}
+	if m.prepare != nil {
+		m.prepare()
+	}
+	if err := installWithRetry(m.privileged, m.argv(pkgs)...); err != nil {
+		return err
+	}
+	if m.after != nil {
+		return m.after(pkgs)
+	}
+	return nil
+}
+
+// installWithRetry reruns a failed install a couple of times. Both apt
+// and brew resume partial downloads from their caches, so a retry after
+// a Wi-Fi blip continues rather than starting over.
+func installWithRetry(privileged bool, argv ...string) error {
+	var err error
+	for attempt := 1; attempt <= 3; attempt++ {
+		if err = runInstaller(privileged, argv...); err == nil {
+			return nil
+		}
+		if printSudoScript {
+			return err
+		}
+		if attempt < 3 {
+			fmt.Printf("  install attempt %d failed, retrying (downloads resume from the cache)\n", attempt)
+		}
+	}
+	return err
+}
+
+// runInstaller runs a package-manager command, streaming its output
+// while keeping the tail. On failure the report shows the exact
+// command, the installer's last lines, and the manual retry - not a
+// generic "failed to install prerequisites".
+func runInstaller(privileged bool, argv ...string) error {
+	if dryRun {
+		fmt.Printf("dry-run: would run: %s\n", strings.Join(argv, " "))
+		return nil
+	}
+	if privileged {
+		if printSudoScript {
+			fmt.Printf("# install packages\n%s\n", shellJoin(argv))
+			return nil
+		}
+		if err := ensureSudoSession(); err != nil {
+			return err
+		}
+		argv = append([]string{"sudo"}, argv...)
+	}
+	transcribe("exec", strings.Join(argv, " "))
+	tail := &tailWriter{max: installTailLines}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = io.MultiWriter(os.Stdout, tail)
+	cmd.Stderr = io.MultiWriter(os.Stderr, tail)
+	if err := cmd.Run(); err != nil {
+		manual := strings.Join(argv, " ")
+		return fmt.Errorf("installer failed (%v)\n  command: %s\n  last output:\n%s  retry manually with: %s",
+			err, manual, tail.indented("    "), manual)
+	}
+	return nil
+}
+
+// tailWriter keeps the last max lines written through it.
+type tailWriter struct {
+	max     int
+	lines   []string
+	partial string
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	text := w.partial + string(p)
+	parts := strings.Split(text, "\n")
+	w.partial = parts[len(parts)-1]
+	for _, line := range parts[:len(parts)-1] {
+		w.lines = append(w.lines, line)
+	}
+	if len(w.lines) > w.max {
+		w.lines = w.lines[len(w.lines)-w.max:]
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) indented(prefix string) string {
+	var b strings.Builder
+	for _, l := range w.lines {
+		b.WriteString(prefix + l + "\n")
+	}
+	return b.String()
+}
+
+func haveExec(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
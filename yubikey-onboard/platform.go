@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// supportedPlatforms mirrors the release matrix; arm64 Linux covers the
+// Raspberry Pi and other dev boards people build on.
+var supportedPlatforms = map[string]bool{
+	"linux/amd64":   true,
+	"linux/arm64":   true,
+	"darwin/amd64":  true,
+	"darwin/arm64":  true,
+	"windows/amd64": true,
+	"windows/arm64": true,
+}
+
+func init() {
+	checks = append(checks, check{"OS compatibility", checkOSCompatibility})
+}
+
+// osRelease returns the ID field of /etc/os-release ("debian",
+// "raspbian", "ubuntu", ...), or "" off Linux.
+func osRelease() string {
+	data, err := os.ReadFile("/etc/os-release")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if v, ok := strings.CutPrefix(line, "ID="); ok {
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
+
+func checkOSCompatibility() error {
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	if !supportedPlatforms[platform] {
+		return fmt.Errorf("%s is not in the supported matrix %v", platform, supportedKeys())
+	}
+	if runtime.GOOS == "windows" {
+		// gpg on Windows means Gpg4win; the MSYS and WSL builds do not
+		// see USB smart cards properly.
+		if !haveExec("gpg") && !gpg4winInstalled() {
+			return fmt.Errorf("Gpg4win not found; install it from gpg4win.org (the WSL/MSYS gpg builds cannot talk to the card)")
+		}
+	}
+	if runtime.GOOS == "linux" {
+		// scdaemon on most ARM boards only reaches the card via pcscd.
+		if runtime.GOARCH == "arm64" && !haveExec("pcscd") {
+			return fmt.Errorf("pcscd is required on arm64 boards; install pcscd and pcsc-tools")
+		}
+		// The agent sockets live under the user runtime dir; headless
+		// boards often lack one until lingering is enabled. Only systemd
+		// provides /run/user - on OpenRC (Alpine containers) gpg falls
+		// back to sockets in the home directory, which is fine.
+		if os.Getenv("XDG_RUNTIME_DIR") == "" && runningSystemd() {
+			if _, err := os.Stat(fmt.Sprintf("/run/user/%d", os.Getuid())); err != nil {
+				return fmt.Errorf("no XDG_RUNTIME_DIR and /run/user/%d is missing; enable lingering: sudo loginctl enable-linger $USER", os.Getuid())
+			}
+		}
+	}
+	return nil
+}
+
+// runningSystemd reports whether systemd is PID 1; the directory is the
+// documented detection point (sd_booted).
+func runningSystemd() bool {
+	info, err := os.Stat("/run/systemd/system")
+	return err == nil && info.IsDir()
+}
+
+func supportedKeys() []string {
+	var keys []string
+	for k := range supportedPlatforms {
+		keys = append(keys, k)
+	}
+	return keys
+}
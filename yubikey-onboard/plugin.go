@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+// External step executables live in <configdir>/steps.d. Each one is
+// offered as an optional setup step, so customer-specific work (their
+// VPN, their CA) plugs in without forking the core.
+//
+// An executable may describe itself by answering `<exe> describe` with
+// JSON: {"name": ..., "desc": ..., "optional": bool, "after": [...]}.
+// Without that, the filename is the name and the step is optional.
+
+func loadExternalSteps() error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	stepsDir := filepath.Join(dir, "steps.d")
+	entries, err := os.ReadDir(stepsDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if info, err := e.Info(); err != nil || info.Mode()&0o111 == 0 {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		registerStep(externalStep(filepath.Join(stepsDir, name)))
+	}
+	return nil
+}
+
+func externalStep(path string) *step {
+	s := &step{
+		name:      filepath.Base(path),
+		desc:      "External step " + filepath.Base(path),
+		optional:  true,
+		exclusive: true,
+	}
+	if out, err := exec.Command(path, "describe").Output(); err == nil {
+		var meta struct {
+			Name     string   `json:"name"`
+			Desc     string   `json:"desc"`
+			Optional *bool    `json:"optional"`
+			After    []string `json:"after"`
+		}
+		if json.Unmarshal(out, &meta) == nil {
+			if meta.Name != "" {
+				s.name = meta.Name
+			}
+			if meta.Desc != "" {
+				s.desc = meta.Desc
+			}
+			if meta.Optional != nil {
+				s.optional = *meta.Optional
+			}
+			s.after = meta.After
+		}
+	}
+	s.run = func(ctx *setupContext) error {
+		cmd := exec.Command(path, "run")
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("external step %s: %w", s.name, err)
+		}
+		return nil
+	}
+	return s
+}
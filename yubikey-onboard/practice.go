@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	register(&command{
+		name:    "practice",
+		summary: "walk through the daily workflows in a scratch sandbox",
+		run:     runPractice,
+	})
+}
+
+// practiceExercise is one hands-on drill: do the thing, verify it, and
+// say what just happened in plain words.
+type practiceExercise struct {
+	title string
+	run   func(id gpgIdentity, dir string) error
+}
+
+var practiceExercises = []practiceExercise{
+	{"a signed git commit", practiceSignedCommit},
+	{"an encrypted file", practiceEncryptedFile},
+	{"a pass entry", practicePassEntry},
+	{"an ssh connection", practiceSSH},
+}
+
+// runPractice rehearses the workflows the card is for - signing,
+// encrypting, pass, ssh - in a throwaway sandbox, verifying each one.
+// Setup proves the machine works; practice makes sure the person can
+// repeat it on their own.
+func runPractice(args []string) error {
+	id, err := primaryIdentity()
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "yko-practice-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	fmt.Println("Practice run. Everything happens in a scratch directory; nothing here is permanent.")
+	passed := 0
+	for i, ex := range practiceExercises {
+		fmt.Printf("\n[%d/%d] Practice: %s\n", i+1, len(practiceExercises), ex.title)
+		if err := ex.run(id, dir); err != nil {
+			fmt.Printf("  %s %v\n", sym().fail, err)
+			if !confirm("Keep going with the remaining exercises?", true) {
+				break
+			}
+			continue
+		}
+		passed++
+	}
+	fmt.Printf("\n%d of %d exercises passed.\n", passed, len(practiceExercises))
+	if passed < len(practiceExercises) {
+		return fmt.Errorf("some exercises failed; run `%s check` for diagnostics", brandProduct)
+	}
+	fmt.Println("That is the whole daily workflow - you are ready.")
+	return nil
+}
+
+func practiceSignedCommit(id gpgIdentity, dir string) error {
+	repo := filepath.Join(dir, "repo")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		return err
+	}
+	git := func(args ...string) (string, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		return strings.TrimSpace(string(out)), err
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.signingkey", id.fingerprint},
+		{"config", "commit.gpgsign", "true"},
+	} {
+		if out, err := git(args...); err != nil {
+			return fmt.Errorf("git %s: %s", strings.Join(args, " "), out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, "README"), []byte("practice\n"), 0o644); err != nil {
+		return err
+	}
+	fmt.Println("  Committing a file. From now on every commit you make is signed")
+	fmt.Println("  like this one - touch the YubiKey if it blinks.")
+	if out, err := git("add", "README"); err != nil {
+		return fmt.Errorf("git add: %s", out)
+	}
+	if out, err := git("commit", "-q", "-m", "my first signed commit"); err != nil {
+		return fmt.Errorf("signed commit failed: %s", out)
+	}
+	if out, err := git("verify-commit", "HEAD"); err != nil {
+		return fmt.Errorf("git verify-commit failed: %s", out)
+	}
+	fmt.Printf("  %s the commit carries a signature only your card could have made\n", sym().ok)
+	return nil
+}
+
+func practiceEncryptedFile(id gpgIdentity, dir string) error {
+	plain := filepath.Join(dir, "note.txt")
+	sealed := plain + ".gpg"
+	const secret = "meet at the usual place\n"
+	if err := os.WriteFile(plain, []byte(secret), 0o600); err != nil {
+		return err
+	}
+	fmt.Println("  Encrypting a note to yourself, then decrypting it with the card.")
+	if out, err := output("gpg", "--batch", "--yes", "--encrypt",
+		"--recipient", id.fingerprint, "--output", sealed, plain); err != nil {
+		return fmt.Errorf("encrypt failed: %v: %s", err, out)
+	}
+	out, err := output("gpg", "--batch", "--decrypt", sealed)
+	if err != nil {
+		return fmt.Errorf("decrypt failed (is the card inserted?): %v", err)
+	}
+	// output() trims trailing whitespace, so compare the trimmed texts.
+	if out != strings.TrimSpace(secret) {
+		return fmt.Errorf("decrypted text does not match what was encrypted")
+	}
+	fmt.Printf("  %s round trip worked - anything encrypted to your key needs the card to read\n", sym().ok)
+	return nil
+}
+
+func practicePassEntry(id gpgIdentity, dir string) error {
+	if !haveExec("pass") {
+		fmt.Println("  pass is not installed; skipping. Secrets at work live in the pass repo.")
+		return nil
+	}
+	const entry = "yubikey-onboard/practice"
+	fmt.Println("  Storing and reading back a secret with pass, the team password store.")
+	insert := exec.Command("pass", "insert", "-m", "-f", entry)
+	insert.Stdin = strings.NewReader("hunter2\n")
+	if out, err := insert.CombinedOutput(); err != nil {
+		return fmt.Errorf("pass insert failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	defer exec.Command("pass", "rm", "-f", entry).Run()
+	out, err := output("pass", "show", entry)
+	if err != nil {
+		return fmt.Errorf("pass show failed: %v", err)
+	}
+	if strings.TrimSpace(out) != "hunter2" {
+		return fmt.Errorf("pass returned something unexpected")
+	}
+	fmt.Printf("  %s the secret was encrypted to your card and decrypted by it\n", sym().ok)
+	return nil
+}
+
+func practiceSSH(id gpgIdentity, dir string) error {
+	host := hostFromURL(giteaURL())
+	if host == "" {
+		fmt.Println("  no forge configured; skipping the ssh exercise.")
+		return nil
+	}
+	fmt.Printf("  Connecting to %s over ssh - the card answers the challenge.\n", host)
+	if err := sshAuthTest("git@" + host); err != nil {
+		return err
+	}
+	fmt.Printf("  %s authenticated without any key file on disk\n", sym().ok)
+	return nil
+}
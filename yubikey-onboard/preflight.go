@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// minimumFreeBytes is the space a full onboarding plausibly needs:
+// packages, a cosign binary, gpg caches.
+const minimumFreeBytes = 500 << 20
+
+// preflight verifies the environment can carry a setup run at all and
+// prints one consolidated report, so the run fails in the first five
+// seconds instead of in step seven.
+func preflight(offline bool) error {
+	type result struct {
+		name string
+		err  error
+	}
+	var results []result
+	add := func(name string, err error) {
+		results = append(results, result{name, err})
+	}
+
+	add("usable terminal", checkTTY())
+	add("free disk space in $HOME", checkDiskSpace())
+	for _, p := range writablePaths() {
+		add("writable "+p, checkWritable(p))
+	}
+	if offline {
+		add("network endpoints", fmt.Errorf("skipped (offline mode)"))
+		results[len(results)-1].err = nil // informational only
+		fmt.Println("Preflight (offline: endpoint checks skipped)")
+	} else {
+		for _, url := range requiredEndpoints() {
+			add("reach "+url, checkReachable(url))
+		}
+		add("clock in sync", checkClockSkew())
+		fmt.Println("Preflight")
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			fmt.Printf("  %s %s: %v\n", sym().fail, r.name, r.err)
+			failed++
+		} else {
+			fmt.Printf("  %s %s\n", sym().ok, r.name)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d preflight check(s) failed; fix these before running setup", failed)
+	}
+	return nil
+}
+
+func checkTTY() error {
+	if nonInteractive || promptAnswers != nil {
+		return nil // scripted runs do not need a terminal
+	}
+	if !stdoutIsTerminal() {
+		return fmt.Errorf("stdout is not a terminal; interactive setup needs one (or use -profile ci / --answers)")
+	}
+	return nil
+}
+
+func checkDiskSpace() error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	free, err := freeDiskBytes(home)
+	if err != nil {
+		return nil // platforms without the syscall skip the check
+	}
+	if free < minimumFreeBytes {
+		return fmt.Errorf("only %d MB free; need at least %d MB", free>>20, minimumFreeBytes>>20)
+	}
+	return nil
+}
+
+func writablePaths() []string {
+	home, _ := os.UserHomeDir()
+	return []string{
+		gpgDirs().Home,
+		filepath.Join(home, ".ssh"),
+		filepath.Join(home, ".gitconfig"),
+	}
+}
+
+// checkWritable probes that the path (or, for files, its directory) can
+// actually be written, catching the root-owned leftovers from sudo runs
+// that checkOwnership reports after the fact.
+func checkWritable(path string) error {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return checkWritableDir(filepath.Dir(path))
+	}
+	if err != nil {
+		return err
+	}
+	if fi.IsDir() {
+		return checkWritableDir(path)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("not writable (run `check` for the ownership diagnosis)")
+	}
+	f.Close()
+	return nil
+}
+
+func checkWritableDir(dir string) error {
+	probe := filepath.Join(dir, ".yko-preflight")
+	if err := os.WriteFile(probe, nil, 0o600); err != nil {
+		return fmt.Errorf("cannot write in %s", dir)
+	}
+	os.Remove(probe)
+	return nil
+}
+
+// requiredEndpoints lists the org services a normal run talks to.
+func requiredEndpoints() []string {
+	var urls []string
+	for _, u := range []string{org().GiteaURL, org().VaultAddr, org().CAURL} {
+		if u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// maxClockSkew is how far the clock may drift before gpg starts
+// producing "key created in the future" style failures.
+const maxClockSkew = 2 * time.Minute
+
+// checkClockSkew compares the system clock against the Date header of
+// an org endpoint - no NTP client needed, and it measures against the
+// servers the signatures actually talk to. On skew it offers a sync.
+func checkClockSkew() error {
+	urls := requiredEndpoints()
+	if len(urls) == 0 {
+		return nil
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Head(urls[0])
+	if err != nil {
+		return nil // reachability has its own check
+	}
+	resp.Body.Close()
+	remote, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return nil
+	}
+	skew := now().Sub(remote)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew <= maxClockSkew {
+		return nil
+	}
+	fmt.Printf("  %s system clock is off by about %s\n", sym().warn, skew.Round(time.Second))
+	if confirm("Trigger a time sync now?", true) {
+		if err := syncClock(); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("clock is %s off server time; gpg and the forge will reject signatures", skew.Round(time.Second))
+}
+
+// syncClock pokes whichever time daemon the machine has.
+func syncClock() error {
+	switch {
+	case haveExec("chronyc"):
+		return runPrivileged("force chrony time step", "chronyc", "makestep")
+	case haveExec("timedatectl"):
+		return runPrivileged("enable systemd NTP sync", "timedatectl", "set-ntp", "true")
+	case haveExec("sntp"):
+		return runPrivileged("one-shot SNTP sync", "sntp", "-sS", "pool.ntp.org")
+	}
+	return fmt.Errorf("no time-sync tool found")
+}
+
+func checkReachable(url string) error {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Head(url)
+	if err != nil {
+		// Reachability is what matters; a TLS or HTTP quirk on HEAD
+		// still proves the network path.
+		if strings.Contains(err.Error(), "certificate") {
+			return nil
+		}
+		return fmt.Errorf("unreachable: %v", err)
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	register(&command{
+		name:    "publish",
+		summary: "publish the public key to keys.openpgp.org and/or a WKD tree",
+		run:     runPublish,
+	})
+}
+
+func runPublish(args []string) error {
+	fs := flag.NewFlagSet("publish", flag.ContinueOnError)
+	keyserver := fs.String("keyserver", "https://keys.openpgp.org", "verifying keyserver to upload to")
+	noUpload := fs.Bool("no-upload", false, "skip the keyserver upload")
+	wkdDir := fs.String("wkd-dir", "", "also write a .well-known/openpgpkey tree under this directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	id, err := primaryIdentity()
+	if err != nil {
+		return err
+	}
+
+	if !*noUpload {
+		if err := uploadToKeyserver(*keyserver, id); err != nil {
+			return err
+		}
+	}
+	if *wkdDir != "" {
+		if err := writeWKDTree(*wkdDir, id); err != nil {
+			return err
+		}
+	}
+	if *noUpload && *wkdDir == "" {
+		return fmt.Errorf("nothing to do: --no-upload given and no --wkd-dir")
+	}
+	return nil
+}
+
+func uploadToKeyserver(server string, id gpgIdentity) error {
+	armored, err := exportPublicKey(id.fingerprint)
+	if err != nil {
+		return err
+	}
+	body, _ := json.Marshal(map[string]string{"keytext": armored})
+	resp, err := http.Post(server+"/vks/v1/upload", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("upload to %s: %w", server, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("upload to %s: HTTP %s", server, resp.Status)
+	}
+	var result struct {
+		Status map[string]string `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("upload to %s: bad response: %v", server, err)
+	}
+	fmt.Printf("Uploaded %s to %s\n", id.fingerprint, server)
+	for addr, state := range result.Status {
+		fmt.Printf("  %s: %s\n", addr, state)
+		if state == "unpublished" || state == "pending" {
+			fmt.Printf("  check %s for the verification mail\n", addr)
+		}
+	}
+	return nil
+}
+
+// writeWKDTree writes the advanced-method Web Key Directory layout
+// (.well-known/openpgpkey/<domain>/hu/<hash>) for the user's address.
+func writeWKDTree(dir string, id gpgIdentity) error {
+	if id.email == "" {
+		return fmt.Errorf("key %s has no email uid; cannot build a WKD path", id.fingerprint)
+	}
+	local, domain, ok := strings.Cut(id.email, "@")
+	if !ok {
+		return fmt.Errorf("malformed email %q in uid", id.email)
+	}
+	raw, err := exportPublicKeyBinary(id.fingerprint)
+	if err != nil {
+		return err
+	}
+	hash := zbase32(sha1.Sum([]byte(strings.ToLower(local))))
+	huDir := filepath.Join(dir, ".well-known", "openpgpkey", strings.ToLower(domain), "hu")
+	if err := os.MkdirAll(huDir, 0o755); err != nil {
+		return err
+	}
+	// The spec requires a policy file, even if empty.
+	policy := filepath.Join(huDir, "..", "policy")
+	if _, err := os.Stat(policy); os.IsNotExist(err) {
+		if err := writeFileAtomic(policy, nil, 0o644); err != nil {
+			return err
+		}
+	}
+	keyPath := filepath.Join(huDir, hash)
+	if err := writeFileAtomic(keyPath, raw, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote WKD entry for %s to %s\n", id.email, keyPath)
+	return nil
+}
+
+// zbase32 encodes a SHA-1 digest with the z-base-32 alphabet used by WKD.
+func zbase32(sum [sha1.Size]byte) string {
+	const alphabet = "ybndrfg8ejkmcpqxot1uwisza345h769"
+	var out strings.Builder
+	var bits, n uint
+	for _, b := range sum {
+		bits = bits<<8 | uint(b)
+		n += 8
+		for n >= 5 {
+			n -= 5
+			out.WriteByte(alphabet[bits>>n&31])
+		}
+	}
+	if n > 0 {
+		out.WriteByte(alphabet[bits<<(5-n)&31])
+	}
+	return out.String()
+}
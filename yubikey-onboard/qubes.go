@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func init() {
+	registerStep(&step{
+		name:      "qubes",
+		desc:      "Configure split-GPG instead of a local agent (Qubes only)",
+		optional:  false,
+		exclusive: true,
+		run:       qubesStep,
+	})
+}
+
+// isQubes detects an AppVM under Qubes OS.
+func isQubes() bool {
+	if _, err := os.Stat("/usr/bin/qubes-gpg-client"); err == nil {
+		return true
+	}
+	_, err := os.Stat("/var/run/qubes")
+	return err == nil
+}
+
+// qubesStep configures qubes-split-gpg. The standard flow (local
+// scdaemon, agent sockets) is wrong under Qubes: the card lives in a
+// backend vault VM and this VM only talks to it over qrexec.
+func qubesStep(ctx *setupContext) error {
+	if !isQubes() {
+		return nil
+	}
+	fmt.Println("  Qubes detected: the normal agent setup does NOT apply here.")
+	if !haveExec("qubes-gpg-client") {
+		return fmt.Errorf("qubes-gpg-split is not installed in this template; install qubes-gpg-split and retry")
+	}
+	backend := promptString("Which VM holds the key (GPG backend domain)?", "vault")
+	if _, err := output("qrexec-client-vm", backend, "qubes.Gpg", "/bin/true"); err != nil {
+		return fmt.Errorf("cannot reach qubes.Gpg on %q; allow it in dom0 policy (/etc/qubes/policy.d)", backend)
+	}
+
+	// Point the split-gpg client at the backend for future shells.
+	home, _ := os.UserHomeDir()
+	profile := home + "/.profile"
+	line := "export QUBES_GPG_DOMAIN=" + backend
+	existing, _ := os.ReadFile(profile)
+	if !strings.Contains(string(existing), line) {
+		f, err := os.OpenFile(profile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(f, line)
+		f.Close()
+	}
+	fmt.Println("  split-GPG configured; use `qubes-gpg-client` (or qubes-gpg-client-wrapper as gpg.program)")
+	fmt.Println("  git config --global gpg.program qubes-gpg-client-wrapper")
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func init() {
+	register(&command{
+		name:    "devcontainer",
+		summary: "emit devcontainer agent-forwarding config, or verify it from inside",
+		run:     runDevcontainer,
+	})
+}
+
+func runDevcontainer(args []string) error {
+	fs := flag.NewFlagSet("devcontainer", flag.ContinueOnError)
+	out := fs.String("out", "", "write the snippet to this file instead of stdout")
+	wsl := fs.Bool("wsl", false, "also print the WSL2 host-side relay snippet")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 && fs.Arg(0) == "verify" {
+		return verifyContainerForwarding()
+	}
+	content := devcontainerRecipe
+	if *wsl {
+		content += "\n" + wslRecipe
+	}
+	if *out == "" {
+		fmt.Print(content)
+		return nil
+	}
+	dst, err := resolveOutputPath(*out)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(dst, []byte(content), 0o644); err != nil {
+		return err
+	}
+	fmt.Println("wrote " + dst)
+	return nil
+}
+
+// verifyContainerForwarding runs inside the container and confirms the
+// forwarded sockets actually work, not just that the mounts exist.
+func verifyContainerForwarding() error {
+	s := sym()
+	failed := false
+
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		fmt.Println(s.fail + " SSH_AUTH_SOCK is unset; the containerEnv block is missing")
+		failed = true
+	} else if fi, err := os.Stat(sock); err != nil || fi.Mode()&os.ModeSocket == 0 {
+		fmt.Printf("%s SSH_AUTH_SOCK=%s is not a socket; the mount did not come through\n", s.fail, sock)
+		failed = true
+	} else {
+		fmt.Println(s.ok + " agent socket mounted")
+	}
+
+	if !failed {
+		if out, err := output("ssh-add", "-L"); err != nil || strings.TrimSpace(out) == "" {
+			fmt.Println(s.fail + " agent answers but serves no keys; is the card plugged into the host?")
+			failed = true
+		} else {
+			fmt.Println(s.ok + " agent serves keys from the host")
+		}
+	}
+
+	if haveExec("gpg") {
+		if _, err := output("gpg", "--card-status"); err != nil {
+			fmt.Println(s.fail + " gpg cannot reach the card; forward the gpg-agent extra socket too")
+			failed = true
+		} else {
+			fmt.Println(s.ok + " gpg sees the card")
+		}
+	}
+
+	if failed {
+		return fmt.Errorf("container forwarding is incomplete")
+	}
+	return nil
+}
+
+// devcontainerRecipe forwards both agent sockets into the container;
+// VS Code forwards SSH itself over remote sessions, the mounts cover
+// local containers and every other client.
+const devcontainerRecipe = `// Merge into .devcontainer/devcontainer.json
+{
+  "mounts": [
+    "source=${localEnv:SSH_AUTH_SOCK},target=/ssh-agent.sock,type=bind",
+    "source=${localEnv:HOME}/.gnupg/S.gpg-agent.extra,target=/gpg-agent.sock,type=bind"
+  ],
+  "containerEnv": {
+    "SSH_AUTH_SOCK": "/ssh-agent.sock"
+  },
+  // Wire the forwarded gpg socket into the container's GNUPGHOME and
+  // confirm the result (run this inside the container):
+  //   yubikey-onboard devcontainer verify
+  "postCreateCommand": "mkdir -p ~/.gnupg && ln -sf /gpg-agent.sock ~/.gnupg/S.gpg-agent"
+}
+`
+
+// wslRecipe bridges the Windows agent into WSL2; the card stays
+// attached to Windows and WSL talks to it through npiperelay + socat.
+const wslRecipe = `# WSL2 host-side relay (add to ~/.bashrc inside WSL)
+# Prereqs (Windows): Gpg4win, npiperelay.exe on the Windows PATH.
+# Prereqs (WSL): apt install socat
+
+export SSH_AUTH_SOCK="$HOME/.ssh/agent.sock"
+if ! ss -a | grep -q "$SSH_AUTH_SOCK"; then
+  rm -f "$SSH_AUTH_SOCK"
+  (setsid socat UNIX-LISTEN:"$SSH_AUTH_SOCK,fork" \
+    EXEC:"npiperelay.exe -ei -s //./pipe/openssh-ssh-agent",nofork &) >/dev/null 2>&1
+fi
+
+export GPG_TTY=$(tty)
+`
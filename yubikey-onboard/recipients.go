@@ -0,0 +1,164 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	register(&command{
+		name:    "pass-recipients",
+		summary: "manage .gpg-id recipients in the team password store",
+		run:     runPassRecipients,
+	})
+}
+
+func runPassRecipients(args []string) error {
+	fs := flag.NewFlagSet("pass-recipients", flag.ContinueOnError)
+	sub := fs.String("p", "", "subfolder of the store whose .gpg-id to edit (default: store root)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	rest := fs.Args()
+	if len(rest) == 0 {
+		return listRecipients(*sub)
+	}
+	switch rest[0] {
+	case "list":
+		return listRecipients(*sub)
+	case "add":
+		if len(rest) != 2 {
+			return fmt.Errorf("usage: pass-recipients [-p subfolder] add <fingerprint|email>")
+		}
+		return editRecipients(*sub, rest[1], true)
+	case "remove":
+		if len(rest) != 2 {
+			return fmt.Errorf("usage: pass-recipients [-p subfolder] remove <fingerprint|email>")
+		}
+		return editRecipients(*sub, rest[1], false)
+	}
+	return fmt.Errorf("unknown subcommand %q (want list, add or remove)", rest[0])
+}
+
+// passStoreDir locates the password store the way pass itself does.
+func passStoreDir() (string, error) {
+	if dir := os.Getenv("PASSWORD_STORE_DIR"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(home, ".password-store")
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("no password store at %s; clone it first (%s)", dir, org().PassRepoURL)
+	}
+	return dir, nil
+}
+
+// gpgIDPath resolves the .gpg-id file governing a subfolder.
+func gpgIDPath(sub string) (string, error) {
+	store, err := passStoreDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(store, sub, ".gpg-id"), nil
+}
+
+func readRecipients(sub string) ([]string, error) {
+	path, err := gpgIDPath(sub)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no .gpg-id for %q: %w", sub, err)
+	}
+	var ids []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			ids = append(ids, line)
+		}
+	}
+	return ids, nil
+}
+
+func listRecipients(sub string) error {
+	ids, err := readRecipients(sub)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		fmt.Println(id + "  " + recipientUID(id))
+	}
+	return nil
+}
+
+// recipientUID resolves a fingerprint to a human-readable uid, best
+// effort; .gpg-id entries may also be emails already.
+func recipientUID(id string) string {
+	out, err := output("gpg", "--list-keys", "--with-colons", id)
+	if err != nil {
+		return "(key not in local keyring)"
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if f := strings.Split(line, ":"); f[0] == "uid" && len(f) > 9 {
+			return f[9]
+		}
+	}
+	return ""
+}
+
+// editRecipients adds or removes one recipient, then lets pass rewrite
+// .gpg-id and re-encrypt everything the file governs. Going through
+// `pass init` rather than editing the file means the entries are
+// actually re-encrypted, which is the step people forget by hand.
+func editRecipients(sub, who string, add bool) error {
+	ids, err := readRecipients(sub)
+	if err != nil {
+		return err
+	}
+	var next []string
+	found := false
+	for _, id := range ids {
+		if strings.EqualFold(id, who) {
+			found = true
+			if !add {
+				continue
+			}
+		}
+		next = append(next, id)
+	}
+	if add {
+		if found {
+			fmt.Println(who + " is already a recipient")
+			return nil
+		}
+		// Insist the key exists locally before pass tries to encrypt to it.
+		if _, err := output("gpg", "--list-keys", who); err != nil {
+			return fmt.Errorf("%s is not in the local keyring; run `team sync` or import their key first", who)
+		}
+		next = append(next, who)
+	} else if !found {
+		return fmt.Errorf("%s is not a recipient of %q", who, sub)
+	}
+
+	passArgs := []string{"init"}
+	if sub != "" {
+		passArgs = append(passArgs, "-p", sub)
+	}
+	passArgs = append(passArgs, next...)
+	cmd := exec.Command("pass", passArgs...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("pass init failed: %w", err)
+	}
+	auditLog(fmt.Sprintf("pass-recipients: add=%v %s in %q", add, who, sub))
+	fmt.Println("Recipients updated and entries re-encrypted; commit and push the store")
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Session recording captures every external command this tool ran, with
+// output and exit status, secrets redacted. A user attaches the file to
+// a bug report; a maintainer replays it and the logic sees exactly the
+// user's environment, gpg quirks included.
+
+// cmdRecord is one executed command.
+type cmdRecord struct {
+	Argv   []string `json:"argv"`
+	Output string   `json:"output"`
+	Err    string   `json:"err,omitempty"`
+}
+
+var (
+	sessionMu   sync.Mutex
+	sessionPath string      // set by -record; empty disables
+	sessionLog  []cmdRecord // commands captured so far
+	// replayQueues holds recorded results keyed by command line, each a
+	// FIFO so repeated invocations replay in original order.
+	replayQueues map[string][]cmdRecord
+)
+
+// recordCommand appends one execution to the session, if recording.
+func recordCommand(argv []string, out string, err error) {
+	if sessionPath == "" {
+		return
+	}
+	rec := cmdRecord{Argv: argv, Output: redact.clean(out)}
+	if err != nil {
+		rec.Err = redact.clean(err.Error())
+	}
+	sessionMu.Lock()
+	sessionLog = append(sessionLog, rec)
+	sessionMu.Unlock()
+}
+
+// saveSession writes the captured commands; called from main on exit.
+func saveSession() {
+	if sessionPath == "" {
+		return
+	}
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+	data, err := json.MarshalIndent(sessionLog, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := writeFileAtomic(sessionPath, append(data, '\n'), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: writing session record: %v\n", brandProduct, err)
+	}
+}
+
+// loadSessionReplay arms replay mode from a recorded session.
+func loadSessionReplay(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var recs []cmdRecord
+	if err := json.Unmarshal(data, &recs); err != nil {
+		return fmt.Errorf("session file %s: %v", path, err)
+	}
+	replayQueues = map[string][]cmdRecord{}
+	for _, r := range recs {
+		key := strings.Join(r.Argv, " ")
+		replayQueues[key] = append(replayQueues[key], r)
+	}
+	return nil
+}
+
+// replayCommand serves a recorded result instead of executing, when
+// replay mode is armed. The bool reports whether replay handled it.
+func replayCommand(argv []string) (string, error, bool) {
+	if replayQueues == nil {
+		return "", nil, false
+	}
+	key := strings.Join(argv, " ")
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+	q := replayQueues[key]
+	if len(q) == 0 {
+		return "", fmt.Errorf("replay: no recorded run of %q", key), true
+	}
+	rec := q[0]
+	if len(q) > 1 {
+		replayQueues[key] = q[1:] // keep the last result for extra calls
+	}
+	if rec.Err != "" {
+		return "", fmt.Errorf("%s", rec.Err), true
+	}
+	return rec.Output, nil, true
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// redactor masks secrets in everything the tool prints, so PINs and
+// tokens cannot end up in logs or diagnostics bundles.
+type redactor struct {
+	mu      sync.RWMutex
+	secrets []string
+}
+
+var redact = &redactor{}
+
+// note registers a concrete secret value for masking.
+func (r *redactor) note(secret string) {
+	if len(secret) < 4 {
+		return // masking tiny strings would mangle ordinary text
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.secrets = append(r.secrets, secret)
+}
+
+// secretPatterns catch secret-shaped values nobody registered.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(token|password|secret|pin)([=:]\s*)\S+`),
+	regexp.MustCompile(`\b[0-9a-f]{40,64}\b`), // hex tokens (Gitea PATs etc.)
+	regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY BLOCK?-----.*?-----END [A-Z ]*PRIVATE KEY BLOCK?-----`),
+}
+
+func (r *redactor) clean(s string) string {
+	r.mu.RLock()
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, "[redacted]")
+	}
+	r.mu.RUnlock()
+	for i, pat := range secretPatterns {
+		if i == 0 {
+			s = pat.ReplaceAllString(s, "$1$2[redacted]")
+		} else {
+			s = pat.ReplaceAllString(s, "[redacted]")
+		}
+	}
+	return s
+}
+
+type redactingWriter struct {
+	dst io.Writer
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.dst, redact.clean(string(p))); err != nil {
+		return 0, err
+	}
+	// Report the original length; callers must not retry the delta
+	// between input and the masked output.
+	return len(p), nil
+}
+
+// installRedaction reroutes stdout and stderr through the redactor.
+// The returned flush must run before exit so nothing is lost in the
+// pipe.
+func installRedaction() (flush func()) {
+	realOut, realErr := os.Stdout, os.Stderr
+	outR, outW, err1 := os.Pipe()
+	errR, errW, err2 := os.Pipe()
+	if err1 != nil || err2 != nil {
+		return func() {}
+	}
+	os.Stdout, os.Stderr = outW, errW
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		io.Copy(&redactingWriter{realOut}, outR)
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(&redactingWriter{realErr}, errR)
+	}()
+	return func() {
+		outW.Close()
+		errW.Close()
+		wg.Wait()
+		os.Stdout, os.Stderr = realOut, realErr
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactRegisteredSecret(t *testing.T) {
+	r := &redactor{}
+	r.note("hunter2secret")
+	got := r.clean("the PIN is hunter2secret, write it down")
+	if strings.Contains(got, "hunter2secret") {
+		t.Fatalf("registered secret leaked: %q", got)
+	}
+}
+
+func TestRedactShortSecretsIgnored(t *testing.T) {
+	r := &redactor{}
+	r.note("ok")
+	if got := r.clean("everything is ok"); got != "everything is ok" {
+		t.Fatalf("short string should not be masked: %q", got)
+	}
+}
+
+func TestRedactKeyValuePatterns(t *testing.T) {
+	r := &redactor{}
+	for _, in := range []string{
+		"token=abc123def",
+		"password: correcthorse",
+		"PIN: 654321",
+	} {
+		got := r.clean(in)
+		if !strings.Contains(got, "[redacted]") {
+			t.Errorf("clean(%q) = %q, want masked", in, got)
+		}
+	}
+}
+
+func TestRedactHexToken(t *testing.T) {
+	r := &redactor{}
+	tok := strings.Repeat("ab12", 10) // 40 hex chars
+	got := r.clean("uploading with " + tok)
+	if strings.Contains(got, tok) {
+		t.Fatalf("hex token leaked: %q", got)
+	}
+}
+
+func TestRedactLeavesFingerprints(t *testing.T) {
+	r := &redactor{}
+	// Fingerprints are upper-case hex and public; they must survive.
+	fpr := "AB12CD34EF56AB12CD34EF56AB12CD34EF56AB12"
+	if got := r.clean("fingerprint " + fpr); !strings.Contains(got, fpr) {
+		t.Fatalf("public fingerprint was masked: %q", got)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// renderer turns command results into output. Command and step logic
+// reports what happened; how it looks (glyphs, TAP, JSON, nothing) is
+// the renderer's business. New formats register in newRenderer without
+// touching any caller.
+type renderer interface {
+	// result reports one named pass/fail outcome.
+	result(name string, err error)
+	// info prints advisory text that is not a result (skipped in
+	// machine formats).
+	info(text string)
+	// close flushes anything buffered and returns how many results
+	// failed.
+	close() int
+}
+
+// outputFormat is the global -format selection.
+var outputFormat = "pretty"
+
+func newRenderer() (renderer, error) {
+	switch outputFormat {
+	case "pretty":
+		return &prettyRenderer{glyphs: true}, nil
+	case "plain":
+		return &prettyRenderer{}, nil
+	case "json":
+		return &jsonRenderer{}, nil
+	case "tap":
+		return &tapRenderer{}, nil
+	case "quiet":
+		return &quietRenderer{}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q (want pretty, plain, json, tap or quiet)", outputFormat)
+}
+
+// prettyRenderer is the human terminal output; with glyphs disabled it
+// doubles as the plain format for logs.
+type prettyRenderer struct {
+	glyphs bool
+	failed int
+}
+
+func (r *prettyRenderer) result(name string, err error) {
+	ok, fail := "ok  ", "FAIL"
+	if r.glyphs {
+		ok, fail = sym().ok, sym().fail
+	}
+	if err != nil {
+		fmt.Printf("%s %s: %v\n", fail, name, err)
+		r.failed++
+	} else {
+		fmt.Printf("%s %s\n", ok, name)
+	}
+}
+
+func (r *prettyRenderer) info(text string) { fmt.Println(text) }
+func (r *prettyRenderer) close() int       { return r.failed }
+
+// jsonRenderer buffers results and emits one checkReport document.
+type jsonRenderer struct {
+	results []checkResult
+	failed  int
+}
+
+func (r *jsonRenderer) result(name string, err error) {
+	res := checkResult{Name: name, OK: err == nil}
+	if err != nil {
+		res.Error = err.Error()
+		r.failed++
+	}
+	r.results = append(r.results, res)
+}
+
+func (r *jsonRenderer) info(string) {}
+
+func (r *jsonRenderer) close() int {
+	report := checkReport{
+		SchemaVersion: checkReportVersion,
+		GeneratedAt:   now().Format("2006-01-02T15:04:05Z07:00"),
+		Failed:        r.failed,
+		Results:       r.results,
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+	return r.failed
+}
+
+// tapRenderer speaks Test Anything Protocol for test harnesses.
+type tapRenderer struct {
+	lines  []string
+	failed int
+}
+
+func (r *tapRenderer) result(name string, err error) {
+	n := len(r.lines) + 1
+	if err != nil {
+		r.lines = append(r.lines, fmt.Sprintf("not ok %d - %s # %v", n, name, err))
+		r.failed++
+	} else {
+		r.lines = append(r.lines, fmt.Sprintf("ok %d - %s", n, name))
+	}
+}
+
+func (r *tapRenderer) info(string) {}
+
+func (r *tapRenderer) close() int {
+	fmt.Printf("1..%d\n", len(r.lines))
+	for _, l := range r.lines {
+		fmt.Println(l)
+	}
+	return r.failed
+}
+
+// quietRenderer prints nothing; callers rely on the exit code.
+type quietRenderer struct{ failed int }
+
+func (r *quietRenderer) result(name string, err error) {
+	if err != nil {
+		r.failed++
+	}
+}
+func (r *quietRenderer) info(string) {}
+func (r *quietRenderer) close() int  { return r.failed }
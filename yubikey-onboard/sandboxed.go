@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+func init() {
+	checks = append(checks, check{"gpg is a native package", checkSandboxedGPG})
+}
+
+// checkSandboxedGPG flags gpg or pinentry delivered via snap/flatpak.
+// Their sandboxes relocate sockets and block pinentry, producing
+// failures that look like card problems.
+func checkSandboxedGPG() error {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	for _, bin := range []string{"gpg", "gpg-agent", "pinentry"} {
+		origin := binaryOrigin(bin)
+		if origin != "" {
+			return fmt.Errorf("%s comes from %s; sandboxed gpg breaks pinentry and agent sockets - install the native package (apt install gnupg pinentry-curses) and make sure it wins in PATH", bin, origin)
+		}
+	}
+	return nil
+}
+
+// binaryOrigin reports "snap" or "flatpak" when the binary resolves
+// into a sandbox mount, and "" for native installs.
+func binaryOrigin(bin string) string {
+	path, err := exec.LookPath(bin)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(path, "/snap/") || strings.Contains(path, "/snapd/"):
+		return "snap"
+	case strings.Contains(path, "/flatpak/") || strings.HasPrefix(path, "/var/lib/flatpak/"):
+		return "flatpak"
+	}
+	return ""
+}
+
+// preferNativeGPG is used during setup: it surfaces the same problem
+// before any step relies on gpg.
+func preferNativeGPG() error {
+	if err := checkSandboxedGPG(); err != nil {
+		if confirm("Install the native gnupg packages now?", true) {
+			return installPackages("gnupg", "pinentry-curses")
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os/user"
+	"runtime"
+	"strings"
+)
+
+func init() {
+	registerStep(&step{
+		name:      "sc-auth",
+		desc:      "Pair the YubiKey for macOS login and screensaver unlock",
+		optional:  true,
+		exclusive: true,
+		run:       scAuthStep,
+	})
+	register(&command{
+		name:    "unpair",
+		summary: "remove the macOS smart card login pairing",
+		run:     runUnpair,
+	})
+}
+
+func scAuthStep(ctx *setupContext) error {
+	if skipPersistent("smart card login pairing") {
+		return nil
+	}
+	if runtime.GOOS != "darwin" {
+		fmt.Println("  smart card login pairing is macOS-only; skipping")
+		return nil
+	}
+	fmt.Println("  Pairing ties local login to the YubiKey's PIV identity.")
+	fmt.Println("  If the key is lost you fall back to your account password, but")
+	fmt.Println("  make sure you KNOW that password before pairing.")
+	if !confirm("Pair the YubiKey for login now?", false) {
+		return nil
+	}
+	hashes, err := scAuthIdentities()
+	if err != nil {
+		return err
+	}
+	if len(hashes) == 0 {
+		return fmt.Errorf("sc_auth sees no identities on the card; enroll a PIV certificate first (mtls step)")
+	}
+	u, err := user.Current()
+	if err != nil {
+		return err
+	}
+	if _, err := output("sc_auth", "pair", "-u", u.Username, "-h", hashes[0]); err != nil {
+		return err
+	}
+	fmt.Println("  paired; lock the screen to test, and run `yubikey-onboard unpair` to undo")
+	return nil
+}
+
+func runUnpair(args []string) error {
+	if runtime.GOOS != "darwin" {
+		return fmt.Errorf("unpair only applies to macOS")
+	}
+	u, err := user.Current()
+	if err != nil {
+		return err
+	}
+	if _, err := output("sc_auth", "unpair", "-u", u.Username); err != nil {
+		return err
+	}
+	fmt.Println("smart card pairing removed for " + u.Username)
+	return nil
+}
+
+// scAuthIdentities returns the public key hashes sc_auth reports for the
+// inserted card.
+func scAuthIdentities() ([]string, error) {
+	out, err := output("sc_auth", "identities")
+	if err != nil {
+		return nil, err
+	}
+	var hashes []string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && len(fields[0]) == 40 {
+			hashes = append(hashes, fields[0])
+		}
+	}
+	return hashes, nil
+}
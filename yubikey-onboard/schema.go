@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+func init() {
+	register(&command{
+		name:    "schema",
+		summary: "print the JSON schema for machine-readable outputs",
+		run:     runSchema,
+	})
+}
+
+// checkReportVersion is bumped whenever checkReport changes shape, so
+// MDM and dashboard consumers can pin what they validate against.
+const checkReportVersion = 1
+
+// checkReport is the machine-readable form of one `check` run.
+type checkReport struct {
+	SchemaVersion int           `json:"schema_version"`
+	GeneratedAt   string        `json:"generated_at"`
+	Failed        int           `json:"failed"`
+	Results       []checkResult `json:"results"`
+}
+
+type checkResult struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// schemas holds the published JSON Schema documents, keyed by the
+// output they describe. They are versioned through $id; consumers
+// should reject documents whose schema_version they do not know.
+var schemas = map[string]string{
+	"check": checkReportSchema,
+}
+
+func runSchema(args []string) error {
+	kind := "check"
+	if len(args) == 1 {
+		kind = args[0]
+	} else if len(args) > 1 {
+		return fmt.Errorf("usage: schema [kind]")
+	}
+	doc, ok := schemas[kind]
+	if !ok {
+		kinds := make([]string, 0, len(schemas))
+		for k := range schemas {
+			kinds = append(kinds, k)
+		}
+		sort.Strings(kinds)
+		return fmt.Errorf("unknown schema %q; available: %v", kind, kinds)
+	}
+	fmt.Println(doc)
+	return nil
+}
+
+const checkReportSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "https://gitea.obmondo.com/schemas/yubikey-onboard/check-report/v1.json",
+  "title": "yubikey-onboard check report",
+  "type": "object",
+  "required": ["schema_version", "generated_at", "failed", "results"],
+  "properties": {
+    "schema_version": {"const": 1},
+    "generated_at": {"type": "string", "format": "date-time"},
+    "failed": {"type": "integer", "minimum": 0},
+    "results": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["name", "ok"],
+        "properties": {
+          "name": {"type": "string"},
+          "ok": {"type": "boolean"},
+          "error": {"type": "string"}
+        }
+      }
+    }
+  }
+}`
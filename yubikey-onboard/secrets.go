@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+func init() {
+	register(&command{
+		name:    "secret",
+		summary: "get or set tool secrets in the OS keychain",
+		run:     runSecret,
+	})
+}
+
+// The tool never writes tokens to plaintext files; they go into the
+// platform keyring: Keychain on macOS, secret-service (via secret-tool)
+// on Linux.
+
+const keyringService = "yubikey-onboard"
+
+func runSecret(args []string) error {
+	if len(args) >= 2 && args[0] == "get" {
+		v, err := secretGet(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println(v)
+		return nil
+	}
+	if len(args) >= 2 && args[0] == "set" {
+		value := ""
+		if len(args) >= 3 {
+			value = args[2]
+		} else {
+			v, err := readSecret("value:")
+			if err != nil {
+				return err
+			}
+			value = v
+		}
+		return secretSet(args[1], value)
+	}
+	return fmt.Errorf("usage: secret get <name> | secret set <name> [value]")
+}
+
+func secretGet(name string) (string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return output("security", "find-generic-password", "-s", keyringService, "-a", name, "-w")
+	default:
+		if !haveExec("secret-tool") {
+			return "", fmt.Errorf("secret-tool not installed (package libsecret-tools)")
+		}
+		return output("secret-tool", "lookup", "service", keyringService, "name", name)
+	}
+}
+
+func secretSet(name, value string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		_, err := output("security", "add-generic-password", "-U",
+			"-s", keyringService, "-a", name, "-w", value)
+		return err
+	default:
+		if !haveExec("secret-tool") {
+			return fmt.Errorf("secret-tool not installed (package libsecret-tools)")
+		}
+		cmd := exec.Command("secret-tool", "store",
+			"--label", keyringService+"/"+name,
+			"service", keyringService, "name", name)
+		cmd.Stdin = strings.NewReader(value)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("secret-tool store: %s", strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+func init() {
+	register(&command{
+		name:    "setup",
+		summary: "run the interactive onboarding steps",
+		run:     runSetup,
+	})
+}
+
+// step is one unit of the onboarding flow. Optional steps are offered
+// with a yes/no prompt; required steps always run.
+type step struct {
+	name     string
+	desc     string
+	optional bool
+	// after names steps that must have run before this one. Steps with
+	// no ordering between them may run in parallel.
+	after []string
+	// exclusive marks steps that talk to the terminal or the card and
+	// therefore must not run concurrently with anything else.
+	exclusive bool
+	// explain is the tutorial-mode text shown before the step runs: what
+	// is about to happen and why it matters. Empty falls back to desc.
+	explain string
+	run     func(ctx *setupContext) error
+}
+
+// explainSteps is set by setup -explain: narrate each step and pause
+// before it, so new hires understand the run instead of trusting it.
+var explainSteps bool
+
+// steps is populated by the files implementing each step, in the order
+// their init functions register them.
+var steps []*step
+
+func registerStep(s *step) {
+	steps = append(steps, s)
+}
+
+// setupContext carries state shared between steps of one setup run.
+type setupContext struct {
+	mu       sync.Mutex
+	id       gpgIdentity
+	idLoaded bool
+}
+
+// identity returns the user's primary key, resolving it once per run.
+func (ctx *setupContext) identity() (gpgIdentity, error) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	if !ctx.idLoaded {
+		id, err := primaryIdentity()
+		if err != nil {
+			return gpgIdentity{}, err
+		}
+		ctx.id = id
+		ctx.idLoaded = true
+	}
+	return ctx.id, nil
+}
+
+func runSetup(args []string) error {
+	fs := flag.NewFlagSet("setup", flag.ContinueOnError)
+	fs.BoolVar(&printSudoScript, "print-sudo-script", false,
+		"print privileged commands instead of running them")
+	profile := fs.String("profile", "", "provisioning profile: ci for unattended build machines")
+	employee := fs.String("employee", "", "import this employee's key from the embedded bundle first")
+	offline := fs.Bool("offline", false, "skip network reachability preflight (air-gapped onboarding)")
+	fs.BoolVar(&explainSteps, "explain", false,
+		"tutorial mode: explain each step and pause for confirmation before it")
+	keepGoing := fs.Bool("continue-on-error", false,
+		"record step failures and keep going, with a summary at the end")
+	fs.BoolVar(&forceGitConfig, "force", false,
+		"overwrite git signing config that differs from the card without asking")
+	answers := fs.String("answers", "", "JSON file mapping prompt text to canned replies")
+	record := fs.String("record-answers", "", "capture this run's answers to a JSON file for replay")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *answers != "" {
+		path, err := resolveExistingFile(*answers)
+		if err != nil {
+			return err
+		}
+		if err := loadAnswers(path); err != nil {
+			return err
+		}
+	}
+	if *record != "" {
+		path, err := resolveOutputPath(*record)
+		if err != nil {
+			return err
+		}
+		startRecordingAnswers()
+		defer func() {
+			if err := saveRecordedAnswers(path); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: recording answers: %v\n", brandProduct, err)
+			}
+		}()
+	}
+	if *employee != "" {
+		if err := importEmployeeKey(*employee); err != nil {
+			return err
+		}
+	}
+	switch *profile {
+	case "":
+	case "ci":
+		return runCIProfile()
+	default:
+		return fmt.Errorf("unknown profile %q", *profile)
+	}
+	if b := org().Banner; b != "" {
+		fmt.Println(banner(b))
+		fmt.Println()
+	}
+	if err := preflight(*offline); err != nil {
+		return err
+	}
+	if err := applyAgentDefaults(); err != nil {
+		return err
+	}
+	if err := ensureCardPresent(); err != nil {
+		return err
+	}
+	if err := loadExternalSteps(); err != nil {
+		return err
+	}
+	if err := preferNativeGPG(); err != nil {
+		return err
+	}
+
+	// Settle all optional-step questions up front so the scheduler can
+	// run without stopping to ask.
+	var selected []*step
+	for _, s := range steps {
+		if s.optional && !confirm(fmt.Sprintf("Run the %s step (%s)?", s.name, s.desc), false) {
+			continue
+		}
+		selected = append(selected, s)
+	}
+
+	waves, err := stepWaves(selected)
+	if err != nil {
+		return err
+	}
+
+	st, err := loadState()
+	if err != nil {
+		return err
+	}
+	run := &setupRun{
+		ctx: &setupContext{}, st: st, total: len(selected),
+		remaining: map[string]bool{},
+		keepGoing: *keepGoing,
+		failed:    map[string]error{},
+		skipped:   map[string]string{},
+	}
+	for _, s := range selected {
+		run.remaining[s.name] = true
+	}
+	for _, wave := range waves {
+		var parallel, serial []*step
+		for _, s := range wave {
+			// Tutorial mode talks to the terminal before every step, so
+			// everything runs serially.
+			if s.exclusive || explainSteps {
+				serial = append(serial, s)
+			} else {
+				parallel = append(parallel, s)
+			}
+		}
+
+		errs := make(chan error, len(parallel))
+		var wg sync.WaitGroup
+		for _, s := range parallel {
+			wg.Add(1)
+			go func(s *step) {
+				defer wg.Done()
+				errs <- run.runStep(s)
+			}(s)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil && !run.keepGoing {
+				return err
+			}
+		}
+		for _, s := range serial {
+			if err := run.runStep(s); err != nil && !run.keepGoing {
+				return err
+			}
+		}
+	}
+	if err := saveState(run.st); err != nil {
+		return err
+	}
+	if len(run.failed) > 0 || len(run.skipped) > 0 {
+		return run.printSummary(selected)
+	}
+
+	fmt.Println("Setup complete.")
+	if w := org().WikiURL; w != "" {
+		fmt.Println("Docs: " + w)
+	}
+	if c := org().Contact; c != "" {
+		fmt.Println(c)
+	}
+	return nil
+}
+
+// setupRun tracks progress of one setup invocation.
+type setupRun struct {
+	mu        sync.Mutex
+	ctx       *setupContext
+	st        *state
+	done      int
+	total     int
+	remaining map[string]bool
+	// keepGoing records failures and continues instead of aborting on
+	// the first one (setup -continue-on-error).
+	keepGoing bool
+	failed    map[string]error
+	skipped   map[string]string // step name -> the failed dependency
+}
+
+func (r *setupRun) runStep(s *step) error {
+	r.mu.Lock()
+	// In keep-going mode a step whose dependency already failed cannot
+	// meaningfully run; record the skip rather than a cascade failure.
+	for _, dep := range s.after {
+		if _, bad := r.failed[dep]; bad {
+			r.skipped[s.name] = dep
+			delete(r.remaining, s.name)
+			fmt.Printf("skipping %s (needs %s, which failed)\n", s.name, dep)
+			r.mu.Unlock()
+			return nil
+		}
+	}
+	r.mu.Unlock()
+
+	r.mu.Lock()
+	r.done++
+	line := fmt.Sprintf("[%d/%d] %s", r.done, r.total, s.desc)
+	if eta := r.etaLocked(); eta != "" {
+		line += " (" + eta + ")"
+	}
+	fmt.Println(line)
+	r.mu.Unlock()
+
+	if explainSteps {
+		text := s.explain
+		if text == "" {
+			text = s.desc
+		}
+		fmt.Println("  " + text)
+		if w := org().WikiURL; w != "" {
+			fmt.Printf("  docs: %s#%s\n", w, s.name)
+		}
+		if !confirm("Run this step now?", true) {
+			return fmt.Errorf("stopped at your request before the %s step", s.name)
+		}
+	}
+
+	start := now()
+	err := s.run(r.ctx)
+	elapsed := now().Sub(start)
+
+	r.mu.Lock()
+	delete(r.remaining, s.name)
+	if err == nil {
+		r.st.recordStepDuration(s.name, elapsed.Seconds())
+	} else {
+		r.failed[s.name] = err
+	}
+	r.mu.Unlock()
+
+	if err != nil {
+		if r.keepGoing {
+			fmt.Printf("%s step %s failed: %v (continuing)\n", sym().fail, s.name, err)
+		}
+		return fmt.Errorf("step %s: %w", s.name, err)
+	}
+	return nil
+}
+
+// printSummary lays out how a keep-going run went: what worked, what
+// failed and why, and what was never attempted, followed by the repair
+// route - so one long unattended run ends in a single actionable report
+// rather than a scrollback of interleaved errors.
+func (r *setupRun) printSummary(selected []*step) error {
+	fmt.Println("\nSetup finished with problems:")
+	for _, s := range selected {
+		switch {
+		case r.failed[s.name] != nil:
+			fmt.Printf("  %s %-18s %v\n", sym().fail, s.name, r.failed[s.name])
+		case r.skipped[s.name] != "":
+			fmt.Printf("  %s %-18s skipped (needs %s)\n", sym().warn, s.name, r.skipped[s.name])
+		default:
+			fmt.Printf("  %s %-18s\n", sym().ok, s.name)
+		}
+	}
+	fmt.Println("\nNext:")
+	fmt.Printf("  %s %s doctor --fix   repairs agent, socket and permission problems\n", sym().bullet, brandProduct)
+	if _, bad := r.failed["sign-test"]; bad {
+		fmt.Printf("  %s %s why-failed     diagnoses the signing failure in detail\n", sym().bullet, brandProduct)
+	}
+	fmt.Printf("  %s rerunning `%s setup` is safe; completed steps re-verify quickly\n", sym().bullet, brandProduct)
+	return fmt.Errorf("%d step(s) failed, %d skipped", len(r.failed), len(r.skipped))
+}
+
+// etaLocked estimates the time left from recorded durations of the
+// steps still to run. Steps never seen before are not counted, and no
+// ETA is shown until at least half the remaining steps have history.
+func (r *setupRun) etaLocked() string {
+	var seconds float64
+	known := 0
+	for name := range r.remaining {
+		if d, ok := r.st.StepDurations[name]; ok {
+			seconds += d
+			known++
+		}
+	}
+	if known*2 < len(r.remaining) {
+		return ""
+	}
+	d := time.Duration(seconds * float64(time.Second)).Round(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("about %ds left", int(d.Seconds()))
+	}
+	return fmt.Sprintf("about %dm left", int(d.Minutes()+0.5))
+}
+
+// stepWaves resolves the `after` edges into execution waves: every step
+// in a wave depends only on steps from earlier waves. Registration
+// order is preserved within a wave. Unknown or skipped dependencies are
+// treated as satisfied; cycles are an error.
+func stepWaves(selected []*step) ([][]*step, error) {
+	present := make(map[string]*step, len(selected))
+	for _, s := range selected {
+		present[s.name] = s
+	}
+	level := make(map[string]int)
+	var resolve func(s *step, trail map[string]bool) (int, error)
+	resolve = func(s *step, trail map[string]bool) (int, error) {
+		if l, ok := level[s.name]; ok {
+			return l, nil
+		}
+		if trail[s.name] {
+			return 0, fmt.Errorf("step dependency cycle involving %q", s.name)
+		}
+		trail[s.name] = true
+		l := 0
+		for _, dep := range s.after {
+			d, ok := present[dep]
+			if !ok {
+				continue // not selected or not registered: satisfied
+			}
+			dl, err := resolve(d, trail)
+			if err != nil {
+				return 0, err
+			}
+			if dl+1 > l {
+				l = dl + 1
+			}
+		}
+		delete(trail, s.name)
+		level[s.name] = l
+		return l, nil
+	}
+
+	max := 0
+	for _, s := range selected {
+		l, err := resolve(s, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+		if l > max {
+			max = l
+		}
+	}
+	waves := make([][]*step, max+1)
+	for _, s := range selected {
+		waves[level[s.name]] = append(waves[level[s.name]], s)
+	}
+	return waves, nil
+}
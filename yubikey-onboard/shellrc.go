@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	registerStep(&step{
+		name:     "shell-env",
+		desc:     "Point every installed shell at the gpg-agent socket",
+		optional: false,
+		after:    []string{"gui-env"},
+		explain: "GPG_TTY and SSH_AUTH_SOCK must be set in whichever shell you " +
+			"actually use. Login and interactive shells read different files, " +
+			"so this step writes a fragment for each installed shell and then " +
+			"spawns each one to prove the variables really arrive.",
+		run: shellEnvStep,
+	})
+}
+
+const shellRCMarker = "# >>> yubikey-onboard agent env >>>"
+const shellRCEndMarker = "# <<< yubikey-onboard agent env <<<"
+
+// shellRCTarget describes one rc file a shell reads and how to spawn
+// that shell so the file is sourced.
+type shellRCTarget struct {
+	shell    string   // binary; skipped when not installed
+	file     string   // rc file receiving the fragment
+	spawn    []string // argv after the binary that runs a -c command
+	fragment string   // shell-syntax block, without markers
+	mode     string   // "login" or "interactive", for the report
+}
+
+// posixFragment works in bash and zsh. The socket is resolved at shell
+// startup rather than baked in, so it survives gnupg upgrades that move
+// the socket directory.
+const posixFragment = `export GPG_TTY=$(tty)
+export SSH_AUTH_SOCK=$(gpgconf --list-dirs agent-ssh-socket)`
+
+const fishFragment = `set -gx GPG_TTY (tty)
+set -gx SSH_AUTH_SOCK (gpgconf --list-dirs agent-ssh-socket)`
+
+// shellRCTargets lists every rc file worth writing on this machine.
+// A machine with login bash and interactive zsh needs both sets; a
+// fragment in a shell nobody runs is harmless.
+func shellRCTargets(home string) []shellRCTarget {
+	bashLogin := filepath.Join(home, ".profile")
+	if _, err := os.Stat(filepath.Join(home, ".bash_profile")); err == nil {
+		// bash ignores .profile when .bash_profile exists.
+		bashLogin = filepath.Join(home, ".bash_profile")
+	}
+	return []shellRCTarget{
+		{"bash", filepath.Join(home, ".bashrc"), []string{"-i", "-c"}, posixFragment, "interactive"},
+		{"bash", bashLogin, []string{"-l", "-c"}, posixFragment, "login"},
+		{"zsh", filepath.Join(home, ".zshrc"), []string{"-i", "-c"}, posixFragment, "interactive"},
+		{"zsh", filepath.Join(home, ".zprofile"), []string{"-l", "-c"}, posixFragment, "login"},
+		{"fish", filepath.Join(home, ".config", "fish", "conf.d", "yubikey-onboard.fish"), []string{"-c"}, fishFragment, "interactive"},
+	}
+}
+
+// extraRCFile is the user-configured rc file from config.json, for
+// setups the shell table above does not know about. It only gets the
+// fragment written; there is no way to know how to spawn it.
+func extraRCFile() (string, bool) {
+	rc := userCfg().ShellRC
+	if rc == "" {
+		return "", false
+	}
+	path, err := resolvePath(rc)
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// shellEnvStep writes the agent-environment fragment into the rc file
+// of every installed shell, for both login and interactive startup,
+// then spawns each combination and checks the variable made it through.
+func shellEnvStep(ctx *setupContext) error {
+	if skipPersistent("shell rc files") {
+		return nil
+	}
+	sock := gpgDirs().AgentSSHSocket
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	loginShell := filepath.Base(os.Getenv("SHELL"))
+
+	for _, t := range shellRCTargets(home) {
+		if !haveExec(t.shell) {
+			continue
+		}
+		if err := ensureManagedBlock(t.file, t.fragment); err != nil {
+			return err
+		}
+		fmt.Printf("  %s (%s %s)\n", t.file, t.shell, t.mode)
+	}
+	if rc, ok := extraRCFile(); ok {
+		if err := ensureManagedBlock(rc, posixFragment); err != nil {
+			return err
+		}
+		fmt.Printf("  %s (from config.json)\n", rc)
+	}
+
+	// Verification: each shell must come up with SSH_AUTH_SOCK pointing
+	// at the agent. This is what the old single-file append could not
+	// promise - the fragment existed, just in a file that shell never read.
+	failed := 0
+	for _, t := range shellRCTargets(home) {
+		if !haveExec(t.shell) {
+			continue
+		}
+		argv := append([]string{}, t.spawn...)
+		argv = append(argv, "echo $SSH_AUTH_SOCK")
+		out, err := exec.Command(t.shell, argv...).Output()
+		got := strings.TrimSpace(string(out))
+		label := t.shell + " " + t.mode
+		if t.shell == loginShell {
+			label += " (your login shell)"
+		}
+		if err != nil || got != sock {
+			fmt.Printf("  %s %s did not pick up the agent socket\n", sym().fail, label)
+			failed++
+			continue
+		}
+		fmt.Printf("  %s %s sees the agent\n", sym().ok, label)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d shell startup mode(s) do not see the agent; something else in the rc files is overriding SSH_AUTH_SOCK", failed)
+	}
+	return nil
+}
+
+// ensureManagedBlock writes our marker-delimited fragment into path,
+// replacing a previous version if one is there, appending otherwise.
+func ensureManagedBlock(path, fragment string) error {
+	block := shellRCMarker + "\n" + fragment + "\n" + shellRCEndMarker + "\n"
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	text := string(existing)
+	if start := strings.Index(text, shellRCMarker); start >= 0 {
+		end := strings.Index(text, shellRCEndMarker)
+		if end < 0 {
+			return fmt.Errorf("%s has a damaged yubikey-onboard block (start marker without end); fix it by hand", path)
+		}
+		end += len(shellRCEndMarker) + 1
+		if end > len(text) {
+			end = len(text)
+		}
+		text = text[:start] + block + text[end:]
+	} else {
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		if text != "" {
+			text += "\n"
+		}
+		text += block
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, []byte(text), 0o644)
+}
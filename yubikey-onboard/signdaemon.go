@@ -0,0 +1,206 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+func init() {
+	register(&command{
+		name:    "sign-daemon",
+		summary: "serve a local signing API over a unix socket",
+		run:     runSignDaemon,
+	})
+}
+
+// signDaemon exposes "sign this" over a unix socket so release tooling
+// can use the plugged-in key without each tool carrying its own gpg
+// plumbing. Access control is the socket's file mode; rate limiting
+// keeps a compromised client from burning through signatures silently.
+type signDaemon struct {
+	mu     sync.Mutex
+	recent []time.Time
+	limit  int
+	window time.Duration
+	// hourly is a hard per-hour quota; approveAbove is the per-hour
+	// volume past which each further signature needs a desktop approval.
+	hourly       int
+	approveAbove int
+	lastHour     []time.Time
+}
+
+func runSignDaemon(args []string) error {
+	fs := flag.NewFlagSet("sign-daemon", flag.ContinueOnError)
+	sock := fs.String("socket", defaultSignSocket(), "unix socket path to listen on")
+	limit := fs.Int("rate", 30, "maximum signatures per minute")
+	hourly := fs.Int("hourly", 200, "maximum signatures per hour (0 disables)")
+	approveAbove := fs.Int("approve-above", 0,
+		"past this many signatures per hour, ask for interactive approval (0 disables)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*sock), 0o700); err != nil {
+		return err
+	}
+	os.Remove(*sock)
+	ln, err := net.Listen("unix", *sock)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(*sock, 0o600); err != nil {
+		return err
+	}
+
+	d := &signDaemon{limit: *limit, window: time.Minute, hourly: *hourly, approveAbove: *approveAbove}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sign", d.handleSign)
+	mux.HandleFunc("/sign-file", d.handleSignFile)
+
+	auditLog("sign-daemon started on " + *sock)
+	fmt.Println("sign-daemon listening on " + *sock)
+	return http.Serve(ln, mux)
+}
+
+func defaultSignSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "yubikey-onboard", "sign.sock")
+	}
+	return filepath.Join(os.TempDir(), "yubikey-onboard-sign.sock")
+}
+
+// allow applies the per-minute and per-hour limits, pruning timestamps
+// outside their windows. It returns whether the request may proceed and
+// whether it needs an interactive approval first.
+func (d *signDaemon) allow() (ok, needsApproval bool, reason string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.recent = pruneBefore(d.recent, now().Add(-d.window))
+	d.lastHour = pruneBefore(d.lastHour, now().Add(-time.Hour))
+	if len(d.recent) >= d.limit {
+		return false, false, fmt.Sprintf("over %d signatures in the last minute", d.limit)
+	}
+	if d.hourly > 0 && len(d.lastHour) >= d.hourly {
+		return false, false, fmt.Sprintf("hourly quota of %d exhausted", d.hourly)
+	}
+	if d.approveAbove > 0 && len(d.lastHour) >= d.approveAbove {
+		needsApproval = true
+	}
+	d.recent = append(d.recent, now())
+	d.lastHour = append(d.lastHour, now())
+	return true, needsApproval, ""
+}
+
+func pruneBefore(ts []time.Time, cutoff time.Time) []time.Time {
+	kept := ts[:0]
+	for _, t := range ts {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// desktopApprove asks the person at the machine to allow one more
+// signature, preferring a graphical dialog since the daemon usually
+// runs detached from a terminal.
+func desktopApprove(what string) bool {
+	question := fmt.Sprintf("Unusual signing volume: allow signing %s?", what)
+	if haveExec("zenity") {
+		err := exec.Command("zenity", "--question", "--title", "yubikey-onboard",
+			"--text", question).Run()
+		return err == nil
+	}
+	if stdoutIsTerminal() {
+		return confirm(question, false)
+	}
+	return false
+}
+
+// handleSign signs the request body and returns the armored detached
+// signature.
+func (d *signDaemon) handleSign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST the data to sign", http.StatusMethodNotAllowed)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(r.Body, 64<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	d.serveSignature(w, func(dst string) error {
+		return writeFileAtomic(dst, data, 0o600)
+	}, fmt.Sprintf("%d bytes via /sign", len(data)))
+}
+
+// handleSignFile signs a file already on disk, for artifacts too large
+// to push through the socket.
+func (d *signDaemon) handleSignFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST with ?path=", http.StatusMethodNotAllowed)
+		return
+	}
+	path := r.URL.Query().Get("path")
+	if !filepath.IsAbs(path) {
+		http.Error(w, "path must be absolute", http.StatusBadRequest)
+		return
+	}
+	d.serveSignature(w, func(dst string) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return writeFileAtomic(dst, data, 0o600)
+	}, path)
+}
+
+// serveSignature runs the rate limit, materialises the payload through
+// stage, signs it and writes the armored signature to the response.
+// Every request lands in the audit log, allowed or not.
+func (d *signDaemon) serveSignature(w http.ResponseWriter, stage func(dst string) error, what string) {
+	ok, needsApproval, reason := d.allow()
+	if !ok {
+		auditLog("sign-daemon REFUSED (" + reason + "): " + what)
+		http.Error(w, reason, http.StatusTooManyRequests)
+		return
+	}
+	if needsApproval && !desktopApprove(what) {
+		auditLog("sign-daemon REFUSED (approval denied): " + what)
+		http.Error(w, "interactive approval denied", http.StatusForbidden)
+		return
+	}
+	tmp, err := os.CreateTemp("", "yko-sign-daemon")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+	defer os.Remove(tmp.Name() + ".asc")
+
+	if err := stage(tmp.Name()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := output("gpg", "--armor", "--detach-sign", tmp.Name()); err != nil {
+		auditLog("sign-daemon FAILED: " + what + ": " + err.Error())
+		http.Error(w, "signing failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sig, err := os.ReadFile(tmp.Name() + ".asc")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	auditLog("sign-daemon signed " + what)
+	w.Write(sig)
+}
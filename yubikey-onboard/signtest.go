@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func init() {
+	registerStep(&step{
+		name:      "sign-test",
+		desc:      "Make a signed commit in a scratch repository and verify it",
+		optional:  false,
+		exclusive: true,
+		explain: "Commit signing is the whole point of the card: it proves your " +
+			"commits came from hardware you hold. This step makes a throwaway " +
+			"signed commit and verifies it, so a broken agent shows up here and " +
+			"not on your first real push.",
+		run: signTestStep,
+	})
+}
+
+func signTestStep(ctx *setupContext) error {
+	id, err := ctx.identity()
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "yko-signtest-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	git := func(args ...string) (string, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		return strings.TrimSpace(string(out)), err
+	}
+
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.signingkey", id.fingerprint},
+		{"config", "commit.gpgsign", "true"},
+	} {
+		if out, err := git(args...); err != nil {
+			return fmt.Errorf("git %s: %s", strings.Join(args, " "), out)
+		}
+	}
+	fmt.Println("  committing; touch the YubiKey if it blinks...")
+	if out, err := git("commit", "-q", "--allow-empty", "-m", "yubikey-onboard signing test"); err != nil {
+		return fmt.Errorf("signed commit failed: %s", out)
+	}
+	out, err := git("verify-commit", "HEAD")
+	if err != nil {
+		return fmt.Errorf("git verify-commit failed: %s", out)
+	}
+	fmt.Println("  signature verified:")
+	for _, line := range strings.Split(out, "\n") {
+		fmt.Println("    " + line)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func init() {
+	registerStep(&step{
+		name:     "ssh-test",
+		desc:     "Prove end-to-end SSH authentication through gpg-agent",
+		optional: false,
+		explain: "Your SSH key lives on the card too, served by gpg-agent instead " +
+			"of ssh-agent. This step connects to the forge to prove the whole " +
+			"chain works before you need it for real work.",
+		run: sshTestStep,
+	})
+}
+
+func sshTestStep(ctx *setupContext) error {
+	// Make sure ssh is actually talking to gpg-agent, not some other
+	// agent the desktop environment started.
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if !strings.Contains(sock, "gpg-agent") {
+		fmt.Printf("  warning: SSH_AUTH_SOCK (%s) does not look like the gpg-agent socket\n", sock)
+	}
+	if err := sshAuthTest("git@" + hostFromURL(giteaURL())); err != nil {
+		return err
+	}
+	if bastion := org().Bastion; bastion != "" {
+		if err := sshAuthTest(bastion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// state is persisted between runs so the tool can track manual items
+// the user still owes (and, later, anything else worth remembering).
+type state struct {
+	Todos map[string]todoItem `json:"todos,omitempty"`
+	// StepDurations remembers how long each setup step took (in
+	// seconds, smoothed over runs) so later runs can show an ETA.
+	StepDurations map[string]float64 `json:"step_durations,omitempty"`
+	// Loan records an active loaner-key provisioning so `return` knows
+	// exactly what to undo.
+	Loan *loanRecord `json:"loan,omitempty"`
+	// CheckHistory remembers, per check, the last observed result and
+	// when it last changed, so --history can date intermittent failures.
+	CheckHistory map[string]checkRecord `json:"check_history,omitempty"`
+}
+
+// checkRecord is one check's standing: its latest result, when that
+// result was first observed, and when the check last ran.
+type checkRecord struct {
+	OK       bool      `json:"ok"`
+	Since    time.Time `json:"since"`
+	LastRun  time.Time `json:"last_run"`
+	LastFail string    `json:"last_fail,omitempty"`
+}
+
+// recordStepDuration folds a new sample into the smoothed duration.
+func (st *state) recordStepDuration(name string, seconds float64) {
+	if st.StepDurations == nil {
+		st.StepDurations = map[string]float64{}
+	}
+	if prev, ok := st.StepDurations[name]; ok {
+		st.StepDurations[name] = prev*0.5 + seconds*0.5
+	} else {
+		st.StepDurations[name] = seconds
+	}
+}
+
+type todoItem struct {
+	Done   bool      `json:"done"`
+	DoneAt time.Time `json:"done_at,omitempty"`
+}
+
+// manualItems are the external actions the tool cannot (yet) do or
+// verify on the user's behalf.
+var manualItems = []struct{ key, desc string }{
+	{"gitea-ssh-key", "Upload the SSH public key to Gitea"},
+	{"gitea-gpg-key", "Upload the GPG public key to Gitea"},
+	{"pass-repo", "Get your key added to the team pass repository"},
+	{"2fa-enrolled", "Enroll the key as a 2FA factor on internal services"},
+}
+
+func statePath() (string, error) {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+	dir := filepath.Join(base, "yubikey-onboard")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "state.json"), nil
+}
+
+func loadState() (*state, error) {
+	path, err := statePath()
+	if err != nil {
+		return nil, err
+	}
+	st := &state{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func saveState(st *state) error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0o600)
+}
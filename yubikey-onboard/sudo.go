@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// All privileged work funnels through runPrivileged so sudo asks for
+// the password once, up front, instead of at unpredictable points in
+// the flow. With -print-sudo-script the commands are printed instead of
+// run, for admins who want to execute them separately.
+
+var (
+	printSudoScript bool
+	sudoOnce        sync.Once
+	sudoErr         error
+)
+
+func runPrivileged(desc string, argv ...string) error {
+	if dryRun {
+		fmt.Printf("dry-run: would run (%s): %s\n", desc, shellJoin(argv))
+		return nil
+	}
+	if printSudoScript {
+		fmt.Printf("# %s\n%s\n", desc, shellJoin(argv))
+		return nil
+	}
+	if err := ensureSudoSession(); err != nil {
+		return err
+	}
+	transcribe("exec", shellJoin(argv))
+	cmd := exec.Command("sudo", argv...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", desc, err)
+	}
+	return nil
+}
+
+// ensureSudoSession validates sudo credentials once and keeps the
+// timestamp fresh for the rest of the run.
+func ensureSudoSession() error {
+	sudoOnce.Do(func() {
+		fmt.Println("Some steps need root; sudo will ask once and the session is reused.")
+		cmd := exec.Command("sudo", "-v")
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			sudoErr = fmt.Errorf("sudo validation failed: %w", err)
+			return
+		}
+		go func() {
+			for range time.Tick(time.Minute) {
+				exec.Command("sudo", "-n", "-v").Run()
+			}
+		}()
+	})
+	return sudoErr
+}
+
+// shellJoin renders an argv as a copy-pastable shell line.
+func shellJoin(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, a := range argv {
+		if strings.ContainsAny(a, " \t'\"$&|;<>()*?") {
+			quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		} else {
+			quoted[i] = a
+		}
+	}
+	return "sudo " + strings.Join(quoted, " ")
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"yubikey-onboard/internal/parse"
+)
+
+func init() {
+	register(&command{
+		name:    "team",
+		summary: "sync colleagues' public keys from WKD and the revocation list",
+		run:     runTeam,
+	})
+}
+
+func runTeam(args []string) error {
+	if len(args) != 1 || args[0] != "sync" {
+		return fmt.Errorf("usage: team sync")
+	}
+	return teamSync()
+}
+
+// teamSync refreshes every keyring entry from WKD and removes keys on
+// the org's signed revocation list. Meant to run from a timer so pass
+// recipients and git verification stay current without anyone
+// remembering to refresh.
+func teamSync() error {
+	before, err := keyringFingerprints()
+	if err != nil {
+		return err
+	}
+
+	var refreshed, failed int
+	for _, email := range before {
+		if email == "" {
+			continue
+		}
+		if err := refreshFromWKD(email); err != nil {
+			failed++
+			fmt.Printf("  %s %s: %v\n", sym().fail, email, err)
+			continue
+		}
+		refreshed++
+	}
+
+	removed, err := applyRevocationList()
+	if err != nil {
+		return err
+	}
+
+	after, err := keyringFingerprints()
+	if err != nil {
+		return err
+	}
+	var added int
+	for fpr := range after {
+		if _, ok := before[fpr]; !ok {
+			added++
+		}
+	}
+
+	fmt.Printf("team sync: %d refreshed, %d new, %d removed, %d unreachable\n",
+		refreshed, added, len(removed), failed)
+	if len(removed) > 0 {
+		auditLog("team sync removed revoked keys: " + strings.Join(removed, " "))
+		fmt.Println("Departed employees' keys removed; re-run the pass recipient update if they appear in .gpg-id files")
+	}
+	return nil
+}
+
+// keyringFingerprints maps every public key fingerprint to its primary
+// uid email.
+func keyringFingerprints() (map[string]string, error) {
+	out, err := output("gpg", "--list-keys", "--with-colons")
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]string{}
+	var current string
+	for _, line := range strings.Split(out, "\n") {
+		f := strings.Split(line, ":")
+		switch f[0] {
+		case "fpr":
+			if current == "" && len(f) > 9 {
+				current = f[9]
+				keys[current] = ""
+			}
+		case "uid":
+			if current != "" && len(f) > 9 && keys[current] == "" {
+				keys[current] = emailFromUID(f[9])
+			}
+		case "pub":
+			current = ""
+		}
+	}
+	return keys, nil
+}
+
+func emailFromUID(uid string) string {
+	if i := strings.Index(uid, "<"); i >= 0 {
+		if j := strings.Index(uid[i:], ">"); j > 0 {
+			return uid[i+1 : i+j]
+		}
+	}
+	if strings.Contains(uid, "@") {
+		return uid
+	}
+	return ""
+}
+
+// refreshFromWKD re-fetches one address's key over WKD and imports it;
+// gpg merges new subkeys, expiry bumps and revocations into the
+// existing entry.
+func refreshFromWKD(email string) error {
+	url, err := wkdURL(email)
+	if err != nil {
+		return err
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		if ks := userCfg().Keyserver; ks != "" {
+			_, kerr := output("gpg", "--keyserver", ks, "--locate-external-keys", email)
+			if kerr == nil {
+				return nil
+			}
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %s", resp.Status)
+	}
+	key, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp("", "yko-team")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	tmp.Write(key)
+	tmp.Close()
+	_, err = output("gpg", "--import", tmp.Name())
+	return err
+}
+
+// applyRevocationList downloads the clearsigned fingerprint list,
+// verifies it was signed by the pinned org key, and deletes the listed
+// keys. Returns the fingerprints actually removed.
+func applyRevocationList() ([]string, error) {
+	url := org().RevocationListURL
+	if url == "" {
+		return nil, nil
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("revocation list %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("revocation list %s: HTTP %s", url, resp.Status)
+	}
+	signed, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "yko-revlist")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	tmp.Write(signed)
+	tmp.Close()
+
+	// A valid signature is not enough: gpg accepts any keyring key,
+	// including colleague keys teamSync just imported from WKD. Only the
+	// pinned org signer may delete keys, so an unset pin fails closed.
+	signer := org().RevocationSignerFingerprint
+	if signer == "" {
+		return nil, fmt.Errorf("revocation_signer_fingerprint is not set in org.json; refusing to act on the revocation list")
+	}
+	status, err := exec.Command("gpg", "--status-fd", "1", "--verify", tmp.Name()).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("revocation list signature invalid: %s", strings.TrimSpace(string(status)))
+	}
+	if fpr := parse.ValidSigFingerprint(string(status)); !strings.EqualFold(fpr, signer) {
+		return nil, fmt.Errorf("revocation list signed by %q, not the org signer %s; ignoring it", fpr, signer)
+	}
+
+	// Only the signed body counts; anything outside the clearsign
+	// wrapper was never covered by the signature.
+	body, err := parse.ClearsignedBody(string(signed))
+	if err != nil {
+		return nil, fmt.Errorf("revocation list: %w", err)
+	}
+
+	var removed []string
+	for _, line := range strings.Split(body, "\n") {
+		fpr := strings.ToUpper(strings.TrimSpace(line))
+		if len(fpr) != 40 || strings.Trim(fpr, "0123456789ABCDEF") != "" {
+			continue
+		}
+		if _, err := output("gpg", "--batch", "--yes", "--delete-keys", fpr); err == nil {
+			removed = append(removed, fpr)
+		}
+	}
+	return removed, nil
+}
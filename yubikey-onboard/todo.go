@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+)
+
+func init() {
+	register(&command{
+		name:    "todo",
+		summary: "show or mark off remaining manual onboarding items",
+		run:     runTodo,
+	})
+}
+
+func runTodo(args []string) error {
+	st, err := loadState()
+	if err != nil {
+		return err
+	}
+	if len(args) == 2 && args[0] == "done" {
+		return markTodoDone(st, args[1])
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: todo [done <item>]")
+	}
+	for _, item := range manualItems {
+		mark := "[ ]"
+		if st.Todos[item.key].Done {
+			mark = "[x]"
+		}
+		fmt.Printf("%s %-14s %s\n", mark, item.key, item.desc)
+	}
+	if n := len(pendingTodos(st)); n > 0 {
+		fmt.Printf("\n%d item(s) remaining; mark one with: yubikey-onboard todo done <item>\n", n)
+	}
+	return nil
+}
+
+func markTodoDone(st *state, key string) error {
+	for _, item := range manualItems {
+		if item.key == key {
+			if st.Todos == nil {
+				st.Todos = map[string]todoItem{}
+			}
+			st.Todos[key] = todoItem{Done: true, DoneAt: now()}
+			return saveState(st)
+		}
+	}
+	return fmt.Errorf("unknown item %q; run `yubikey-onboard todo` to list them", key)
+}
+
+func pendingTodos(st *state) []string {
+	var pending []string
+	for _, item := range manualItems {
+		if !st.Todos[item.key].Done {
+			pending = append(pending, item.desc)
+		}
+	}
+	return pending
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// With -transcript FILE every prompt, answer and external command is
+// appended to FILE (secrets redacted), so helpdesk-driven sessions
+// leave a record both parties can review.
+
+var (
+	transcriptMu sync.Mutex
+	transcriptF  *os.File
+)
+
+func openTranscript(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	transcriptF = f
+	fixOwnership(path)
+	transcribe("session", fmt.Sprintf("%s %s started", brandProduct, buildVersion().Version))
+	return nil
+}
+
+// transcribe appends one redacted line to the transcript, if enabled.
+func transcribe(kind, text string) {
+	if transcriptF == nil {
+		return
+	}
+	transcriptMu.Lock()
+	defer transcriptMu.Unlock()
+	fmt.Fprintf(transcriptF, "%s [%s] %s\n",
+		now().Format("2006-01-02T15:04:05Z07:00"), kind, redact.clean(text))
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// Prompt timeout handling, for MDM-driven runs where nobody is at the
+// keyboard. Zero means wait forever; on expiry the action decides
+// whether the run continues with defaults, retries once, or aborts.
+var (
+	promptTimeout       time.Duration
+	promptTimeoutAction = "abort"
+)
+
+var errPromptTimeout = fmt.Errorf("prompt timed out")
+
+// readPromptLine reads a line from stdin, honouring the global prompt
+// timeout. The reader goroutine keeps the buffered stdin consistent: a
+// late answer to a timed-out prompt is consumed, not replayed into the
+// next prompt.
+func readPromptLine() (string, error) {
+	if promptTimeout <= 0 {
+		return stdin.ReadString('\n')
+	}
+	type lineOrErr struct {
+		line string
+		err  error
+	}
+	ch := make(chan lineOrErr, 1)
+	go func() {
+		line, err := stdin.ReadString('\n')
+		ch <- lineOrErr{line, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.line, r.err
+	case <-time.After(promptTimeout):
+		return "", errPromptTimeout
+	}
+}
+
+// promptTimedOut applies the configured timeout action. It returns
+// true when the caller should retry the prompt once; otherwise the
+// caller proceeds with its default answer (the abort action ends the
+// prompt loop by making the default stand and failing the run later is
+// up to the caller's error).
+func promptTimedOut(question string, retried *bool) bool {
+	transcribe("answer", "(timed out)")
+	switch promptTimeoutAction {
+	case "retry":
+		if !*retried {
+			*retried = true
+			fmt.Println("\n(no answer, asking once more)")
+			return true
+		}
+	case "skip":
+		fmt.Println("\n(no answer, continuing with the default)")
+		return false
+	}
+	// abort: the default action for unattended safety.
+	fmt.Println("\n(no answer, aborting)")
+	panic(exitPrompt{})
+}
+
+// exitPrompt unwinds to main when a prompt times out under the abort
+// action; recovering there keeps the redaction flush and exit code.
+type exitPrompt struct{}
+
+// asciiFlag forces plain ASCII markers; set from the global -ascii flag.
+var asciiFlag bool
+
+// glyphs are the decorative characters the tool prints. Serial consoles
+// and some ssh clients mangle anything beyond ASCII, so every glyph has
+// a plain fallback.
+type glyphs struct {
+	ok, fail, warn, bullet, hbar string
+}
+
+var unicodeGlyphs = glyphs{ok: "✓", fail: "✗", warn: "!", bullet: "•", hbar: "─"}
+var asciiGlyphs = glyphs{ok: "ok", fail: "XX", warn: "!", bullet: "*", hbar: "-"}
+
+// sym picks the glyph set: ASCII when asked for explicitly or when the
+// locale does not advertise UTF-8.
+func sym() glyphs {
+	if asciiFlag {
+		return asciiGlyphs
+	}
+	for _, env := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			if strings.Contains(strings.ToUpper(v), "UTF-8") || strings.Contains(strings.ToUpper(v), "UTF8") {
+				return unicodeGlyphs
+			}
+			return asciiGlyphs
+		}
+	}
+	return asciiGlyphs
+}
+
+// termWidth returns the terminal width, falling back to 80 columns when
+// it cannot be determined (pipes, dumb terminals).
+func termWidth() int {
+	if c := os.Getenv("COLUMNS"); c != "" {
+		if n, err := strconv.Atoi(c); err == nil && n > 20 {
+			return n
+		}
+	}
+	if tty, err := os.Open("/dev/tty"); err == nil {
+		defer tty.Close()
+		cmd := exec.Command("stty", "size")
+		cmd.Stdin = tty
+		if out, err := cmd.Output(); err == nil {
+			fields := strings.Fields(string(out))
+			if len(fields) == 2 {
+				if n, err := strconv.Atoi(fields[1]); err == nil && n > 20 {
+					return n
+				}
+			}
+		}
+	}
+	return 80
+}
+
+// stdoutIsTerminal reports whether stdout goes to a terminal; wrapped
+// display output is only for humans, never for pipes.
+func stdoutIsTerminal() bool {
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// wrapLine breaks a long single-line value (such as an SSH key) at the
+// terminal width, marking continuations with a trailing backslash so
+// nothing is silently truncated.
+func wrapLine(s string, width int) string {
+	if width < 20 {
+		width = 20
+	}
+	var b strings.Builder
+	for len(s) > width-1 {
+		b.WriteString(s[:width-1] + "\\\n")
+		s = s[width-1:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// banner draws text inside a horizontal rule sized to the terminal.
+func banner(text string) string {
+	width := termWidth()
+	if width > 100 {
+		width = 100
+	}
+	rule := strings.Repeat(sym().hbar, width)
+	return rule + "\n" + text + "\n" + rule
+}
+
+// nonInteractive suppresses all prompting; every question resolves to
+// its default. Set by unattended profiles such as setup --profile ci.
+var nonInteractive bool
+
+// confirm asks a yes/no question and returns the answer, falling back to
+// def on empty input.
+func confirm(question string, def bool) bool {
+	if dryRun {
+		fmt.Printf("dry-run: %s -> %v\n", question, def)
+		return def
+	}
+	if nonInteractive {
+		transcribe("prompt", question)
+		transcribe("answer", fmt.Sprintf("(non-interactive default %v)", def))
+		return def
+	}
+	if v, ok := answerFor(question); ok {
+		transcribe("prompt", question)
+		transcribe("answer", v+" (from answers file)")
+		return v == "y" || v == "yes" || v == "true"
+	}
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	retried := false
+ask:
+	fmt.Printf("%s [%s] ", question, hint)
+	transcribe("prompt", question)
+	line, err := readPromptLine()
+	if err == errPromptTimeout {
+		if promptTimedOut(question, &retried) {
+			goto ask
+		}
+		return def
+	}
+	if err != nil {
+		transcribe("answer", fmt.Sprintf("(default %v)", def))
+		return def
+	}
+	answer := def
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		answer = true
+	case "n", "no":
+		answer = false
+	}
+	transcribe("answer", fmt.Sprintf("%v", answer))
+	if answer {
+		recordAnswer(question, "yes")
+	} else {
+		recordAnswer(question, "no")
+	}
+	return answer
+}
+
+// readSecret reads a line without echoing it to the terminal.
+func readSecret(promptText string) (string, error) {
+	if v, ok := answerFor(promptText); ok {
+		redact.note(v)
+		transcribe("prompt", promptText)
+		transcribe("answer", "[secret from answers file]")
+		return v, nil
+	}
+	if dryRun {
+		return "", fmt.Errorf("dry-run: would prompt for a secret: %s", promptText)
+	}
+	if nonInteractive {
+		return "", fmt.Errorf("cannot prompt for a secret in non-interactive mode: %s", promptText)
+	}
+	fmt.Print(promptText + " ")
+	transcribe("prompt", promptText)
+	if err := exec.Command("stty", "-F", "/dev/tty", "-echo").Run(); err == nil {
+		defer func() {
+			exec.Command("stty", "-F", "/dev/tty", "echo").Run()
+			fmt.Println()
+		}()
+	}
+	line, err := stdin.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	secret := strings.TrimSpace(line)
+	redact.note(secret)
+	transcribe("answer", "[secret]")
+	return secret, nil
+}
+
+// promptString asks for a line of input, returning def on empty input.
+func promptString(question, def string) string {
+	if dryRun {
+		fmt.Printf("dry-run: %s -> %q\n", question, def)
+		return def
+	}
+	if nonInteractive {
+		transcribe("prompt", question)
+		transcribe("answer", def)
+		return def
+	}
+	if v, ok := answerFor(question); ok {
+		transcribe("prompt", question)
+		transcribe("answer", v+" (from answers file)")
+		return v
+	}
+	retried := false
+ask:
+	if def != "" {
+		fmt.Printf("%s [%s] ", question, def)
+	} else {
+		fmt.Printf("%s ", question)
+	}
+	line, err := readPromptLine()
+	if err == errPromptTimeout {
+		if promptTimedOut(question, &retried) {
+			goto ask
+		}
+		return def
+	}
+	if err != nil {
+		return def
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		line = def
+	}
+	transcribe("prompt", question)
+	transcribe("answer", line)
+	recordAnswer(question, line)
+	return line
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+func init() {
+	register(&command{
+		name:    "usb-diag",
+		summary: "hammer the card for a while to confirm or rule out a flaky hub",
+		run:     runUSBDiag,
+	})
+}
+
+// runUSBDiag performs card transactions in a loop for a fixed period
+// and counts the ones that fail or come back with a different serial,
+// then correlates with the kernel's USB messages from the same window.
+// "My key keeps disappearing" reports are almost always the dock, and
+// this produces the evidence one way or the other.
+func runUSBDiag(args []string) error {
+	fs := flag.NewFlagSet("usb-diag", flag.ContinueOnError)
+	duration := fs.Duration("duration", time.Minute, "how long to keep polling the card")
+	interval := fs.Duration("interval", 2*time.Second, "pause between transactions")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fmt.Printf("Polling the card every %s for %s. Leave the key where it normally sits -\n", interval, duration)
+	fmt.Println("in the dock or hub you suspect - and do not touch it.")
+
+	start := now()
+	deadline := start.Add(*duration)
+	var (
+		transactions int
+		failures     int
+		lastSerial   string
+		reconnects   int
+		failTimes    []time.Time
+	)
+	for now().Before(deadline) {
+		transactions++
+		serial, err := cardSerial()
+		switch {
+		case err != nil:
+			failures++
+			failTimes = append(failTimes, now())
+			fmt.Printf("  %s %s card did not answer\n", sym().fail, now().Format("15:04:05"))
+		case lastSerial != "" && serial != lastSerial:
+			reconnects++
+			fmt.Printf("  %s %s card came back as a different reader\n", sym().warn, now().Format("15:04:05"))
+		}
+		if err == nil {
+			lastSerial = serial
+		}
+		time.Sleep(*interval)
+	}
+
+	kernelEvents := usbKernelEvents(start)
+
+	fmt.Println()
+	fmt.Printf("  transactions:        %d\n", transactions)
+	fmt.Printf("  failed transactions: %d\n", failures)
+	fmt.Printf("  reader changes:      %d\n", reconnects)
+	fmt.Printf("  kernel USB events:   %d\n", len(kernelEvents))
+	for _, line := range kernelEvents {
+		fmt.Println("    " + line)
+	}
+
+	switch {
+	case failures == 0 && len(kernelEvents) == 0:
+		fmt.Printf("  %s the connection held for the whole run; the hub is not the problem\n", sym().ok)
+	case failures > 0 && len(kernelEvents) > 0:
+		fmt.Printf("  %s transaction failures line up with kernel resets - this hub or dock is\n", sym().fail)
+		fmt.Println("  dropping the device. Plug the key into the laptop directly.")
+	case failures > 0:
+		fmt.Printf("  %s the card stopped answering without kernel resets; suspect the smart-card\n", sym().warn)
+		fmt.Println("  stack rather than the hardware (try `check` and restarting pcscd)")
+	default:
+		fmt.Printf("  %s the kernel logged USB events but every transaction succeeded; keep an\n", sym().warn)
+		fmt.Println("  eye on it - that is often autosuspend cycling the port")
+	}
+	if failures > 0 {
+		auditLog(fmt.Sprintf("usb-diag: %d/%d transactions failed, %d kernel events", failures, transactions, len(kernelEvents)))
+	}
+	return nil
+}
+
+// usbKernelEvents returns kernel log lines since start that look like
+// USB trouble (resets, disconnects) for a Yubico or CCID device.
+func usbKernelEvents(start time.Time) []string {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	out, err := output("journalctl", "-k", "--no-pager", "-o", "short-iso",
+		"--since", start.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		// Unprivileged users may not read the kernel journal; dmesg is a
+		// second chance but has no --since, so filtering is all we get.
+		if out, err = output("dmesg"); err != nil {
+			return nil
+		}
+	}
+	var events []string
+	for _, line := range strings.Split(out, "\n") {
+		l := strings.ToLower(line)
+		if !strings.Contains(l, "usb") {
+			continue
+		}
+		if strings.Contains(l, "reset") || strings.Contains(l, "disconnect") ||
+			strings.Contains(l, "device descriptor read") {
+			events = append(events, strings.TrimSpace(line))
+		}
+	}
+	return events
+}
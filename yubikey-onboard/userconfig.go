@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// userConfig holds per-user (or IT-pushed) defaults that would
+// otherwise be flags on every run. It lives in config.json inside the
+// tool's config dir - JSON because the tool is stdlib-only - and can be
+// pointed elsewhere with the global -config flag. Unlike org.json this
+// is about this machine's preferences, not the organization.
+type userConfig struct {
+	// Keyserver is the fallback for key fetches when WKD has no answer.
+	Keyserver string `json:"keyserver"`
+	// PINCacheTTLSeconds is written to gpg-agent.conf as
+	// default-cache-ttl, for teams that want shorter or longer caching.
+	PINCacheTTLSeconds int `json:"pin_cache_ttl_seconds"`
+	// Pinentry pins a specific pinentry program instead of the distro
+	// alternative.
+	Pinentry string `json:"pinentry"`
+	// EmailDomain completes bare usernames in email arguments.
+	EmailDomain string `json:"email_domain"`
+	// ShellRC is an extra shell rc file that should also receive the
+	// agent-environment fragment.
+	ShellRC string `json:"shell_rc"`
+	// KeyExportPath overrides where the armored public key is written.
+	KeyExportPath string `json:"key_export_path"`
+}
+
+// userConfigPath is the -config override; empty means the config dir.
+var userConfigPath string
+
+var (
+	userCfgOnce sync.Once
+	userCfgVal  userConfig
+)
+
+// userCfg returns the user defaults, loading them once. A missing file
+// is simply all defaults; a damaged one is reported and ignored, the
+// same stance org() takes.
+func userCfg() userConfig {
+	userCfgOnce.Do(func() {
+		path := userConfigPath
+		if path == "" {
+			dir, err := configDir()
+			if err != nil {
+				return
+			}
+			path = filepath.Join(dir, "config.json")
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if userConfigPath != "" {
+				fmt.Fprintf(os.Stderr, "%s: cannot read -config file: %v\n", brandProduct, err)
+			}
+			return
+		}
+		if err := json.Unmarshal(data, &userCfgVal); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: ignoring invalid %s: %v\n", brandProduct, path, err)
+			userCfgVal = userConfig{}
+		}
+	})
+	return userCfgVal
+}
+
+// applyAgentDefaults writes the agent-related user defaults into
+// gpg-agent.conf. Called from setup so a pushed config takes effect on
+// the next run without anyone knowing the gpg-agent.conf syntax.
+func applyAgentDefaults() error {
+	cfg := userCfg()
+	if cfg.PINCacheTTLSeconds == 0 && cfg.Pinentry == "" {
+		return nil
+	}
+	conf := filepath.Join(gpgDirs().Home, "gpg-agent.conf")
+	if ttl := cfg.PINCacheTTLSeconds; ttl > 0 {
+		if err := appendConfigLine(conf, "default-cache-ttl "+strconv.Itoa(ttl)); err != nil {
+			return err
+		}
+	}
+	if p := cfg.Pinentry; p != "" {
+		path, err := resolveExistingFile(p)
+		if err != nil {
+			return fmt.Errorf("pinentry from config: %w", err)
+		}
+		if err := appendConfigLine(conf, "pinentry-program "+path); err != nil {
+			return err
+		}
+	}
+	output("gpgconf", "--kill", "gpg-agent")
+	return nil
+}
+
+// completeEmail appends the configured email domain to a bare
+// username, so `-email jdoe` works once IT ships the domain.
+func completeEmail(email string) string {
+	if email == "" || userCfg().EmailDomain == "" {
+		return email
+	}
+	for _, c := range email {
+		if c == '@' {
+			return email
+		}
+	}
+	return email + "@" + userCfg().EmailDomain
+}
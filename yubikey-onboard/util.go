@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// now is a variable so tests can pin the clock.
+var now = time.Now
+
+// output runs a program and returns its trimmed stdout. In replay mode
+// the recorded result is served instead of executing anything.
+func output(name string, args ...string) (string, error) {
+	argv := append([]string{name}, args...)
+	if out, err, handled := replayCommand(argv); handled {
+		return out, err
+	}
+	transcribe("exec", name+" "+strings.Join(args, " "))
+	out, err := exec.Command(name, args...).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			err = fmt.Errorf("%s: %s", name, strings.TrimSpace(string(ee.Stderr)))
+		} else {
+			err = fmt.Errorf("%s: %w", name, err)
+		}
+		recordCommand(argv, "", err)
+		return "", err
+	}
+	trimmed := strings.TrimSpace(string(out))
+	recordCommand(argv, trimmed, nil)
+	return trimmed, nil
+}
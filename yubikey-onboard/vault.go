@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// vaultClient is a minimal client for the Vault HTTP API; enough for
+// KV reads/writes without pulling in the official SDK.
+type vaultClient struct {
+	addr  string
+	token string
+}
+
+// vaultLogin authenticates against Vault. Preference order: an explicit
+// VAULT_TOKEN, a cached CLI token (~/.vault-token, i.e. `vault login
+// -method=oidc` was run), then AppRole credentials for machines.
+func vaultLogin() (*vaultClient, error) {
+	addr := org().VaultAddr
+	if v := os.Getenv("VAULT_ADDR"); v != "" {
+		addr = v
+	}
+	if addr == "" {
+		return nil, fmt.Errorf("no Vault address configured (vault_addr in org.json or VAULT_ADDR)")
+	}
+	addr = strings.TrimRight(addr, "/")
+
+	if t := os.Getenv("VAULT_TOKEN"); t != "" {
+		return &vaultClient{addr: addr, token: t}, nil
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if data, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+			return &vaultClient{addr: addr, token: strings.TrimSpace(string(data))}, nil
+		}
+	}
+	roleID, secretID := os.Getenv("VAULT_ROLE_ID"), os.Getenv("VAULT_SECRET_ID")
+	if roleID != "" && secretID != "" {
+		return appRoleLogin(addr, roleID, secretID)
+	}
+	return nil, fmt.Errorf("no Vault credentials; run `vault login -method=oidc` or set VAULT_ROLE_ID/VAULT_SECRET_ID")
+}
+
+func appRoleLogin(addr, roleID, secretID string) (*vaultClient, error) {
+	body, _ := json.Marshal(map[string]string{"role_id": roleID, "secret_id": secretID})
+	resp, err := http.Post(addr+"/v1/auth/approle/login", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("vault approle login: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault approle login: HTTP %s", resp.Status)
+	}
+	var out struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &vaultClient{addr: addr, token: out.Auth.ClientToken}, nil
+}
+
+// readKV reads a KV v2 secret and returns its data map.
+func (c *vaultClient) readKV(path string) (map[string]string, error) {
+	req, _ := http.NewRequest("GET", c.addr+"/v1/"+kvDataPath(path), nil)
+	req.Header.Set("X-Vault-Token", c.token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault read %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault read %s: HTTP %s", path, resp.Status)
+	}
+	var out struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Data.Data, nil
+}
+
+// writeKV writes a KV v2 secret.
+func (c *vaultClient) writeKV(path string, data map[string]string) error {
+	body, _ := json.Marshal(map[string]interface{}{"data": data})
+	req, _ := http.NewRequest("POST", c.addr+"/v1/"+kvDataPath(path), bytes.NewReader(body))
+	req.Header.Set("X-Vault-Token", c.token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault write %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("vault write %s: HTTP %s", path, resp.Status)
+	}
+	return nil
+}
+
+func kvDataPath(path string) string {
+	mount := org().VaultKVMount
+	if mount == "" {
+		mount = "secret"
+	}
+	return mount + "/data/" + strings.TrimPrefix(path, "/")
+}
+
+// provisioningBundle is what an admin-run provisioning may pre-stage in
+// Vault under yubikey/provision/<email>.
+type provisioningBundle struct {
+	PublicKey       string
+	Policy          string
+	InitialPIN      string
+	InitialAdminPIN string
+}
+
+func vaultProvisioning(email string) (*provisioningBundle, error) {
+	c, err := vaultLogin()
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.readKV("yubikey/provision/" + strings.ToLower(email))
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return &provisioningBundle{
+		PublicKey:       data["public_key"],
+		Policy:          data["policy"],
+		InitialPIN:      data["initial_pin"],
+		InitialAdminPIN: data["initial_admin_pin"],
+	}, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+// Injected at build time:
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+func init() {
+	register(&command{
+		name:    "version",
+		summary: "print the tool version",
+		run:     runVersion,
+	})
+}
+
+// versionInfo is the machine-readable shape, also attached to reports.
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	Go        string `json:"go"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+func buildVersion() versionInfo {
+	v := versionInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		Go:        runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	// Fall back to VCS stamping for `go build` without ldflags.
+	if v.Commit == "" {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, s := range info.Settings {
+				switch s.Key {
+				case "vcs.revision":
+					v.Commit = s.Value
+				case "vcs.time":
+					if v.BuildDate == "" {
+						v.BuildDate = s.Value
+					}
+				}
+			}
+		}
+	}
+	return v
+}
+
+func runVersion(args []string) error {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print version info as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	v := buildVersion()
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	line := brandProduct + " " + v.Version
+	if v.Commit != "" {
+		short := v.Commit
+		if len(short) > 12 {
+			short = short[:12]
+		}
+		line += " (" + short
+		if v.BuildDate != "" {
+			line += ", " + v.BuildDate
+		}
+		line += ")"
+	}
+	fmt.Println(line)
+	return nil
+}
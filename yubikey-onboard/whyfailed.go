@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func init() {
+	register(&command{
+		name:    "why-failed",
+		summary: "reproduce a signing failure and explain the likely cause",
+		run:     runWhyFailed,
+	})
+}
+
+func runWhyFailed(args []string) error {
+	id, err := primaryIdentity()
+	if err != nil {
+		return err
+	}
+	fmt.Println("attempting a test signature (touch the key if it blinks)...")
+	cmd := exec.Command("gpg", "--status-fd=2", "-vv", "--local-user", id.fingerprint, "--clearsign")
+	cmd.Stdin = strings.NewReader("yubikey-onboard signing probe\n")
+	var stderr bytes.Buffer
+	cmd.Stdout = nil
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err == nil {
+		fmt.Println("signing works - if git still fails, compare `git config user.signingkey` with your key")
+		return nil
+	}
+
+	diag := diagnoseSigningFailure(stderr.String())
+	fmt.Println("signing failed.")
+	fmt.Println("cause: " + diag.cause)
+	fmt.Println("fix:   " + diag.fix)
+	fmt.Println("\nfull gpg output follows:")
+	fmt.Println(stderr.String())
+	return fmt.Errorf("signing is broken")
+}
+
+type signingDiagnosis struct {
+	cause, fix string
+}
+
+// diagnoseSigningFailure maps gpg's verbose output to the handful of
+// failures that make up nearly every support ticket.
+func diagnoseSigningFailure(out string) signingDiagnosis {
+	switch {
+	case strings.Contains(out, "Inappropriate ioctl") || (os.Getenv("GPG_TTY") == "" && strings.Contains(out, "pinentry")):
+		return signingDiagnosis{
+			"gpg cannot find your terminal for the PIN prompt (GPG_TTY is unset)",
+			`add 'export GPG_TTY=$(tty)' to your shell rc and restart the shell`,
+		}
+	case strings.Contains(out, "No pinentry") || strings.Contains(out, "pinentry") && strings.Contains(out, "not found"):
+		return signingDiagnosis{
+			"no pinentry program is installed or configured",
+			"install pinentry (e.g. pinentry-curses) and run: gpgconf --kill gpg-agent",
+		}
+	case strings.Contains(out, "Card removed") || strings.Contains(out, "No such device") || strings.Contains(out, "card not present"):
+		return signingDiagnosis{
+			"the YubiKey is not plugged in (or the reader lost it)",
+			"re-insert the key, then run: gpg --card-status",
+		}
+	case strings.Contains(out, "Operation cancelled") || strings.Contains(out, "SIGN_CANCELLED"):
+		return signingDiagnosis{
+			"the operation timed out or was cancelled - most often a missed touch",
+			"retry and watch for the blinking key; it wants a touch within ~15 seconds",
+		}
+	case strings.Contains(out, "Bad PIN") || strings.Contains(out, "PIN blocked"):
+		return signingDiagnosis{
+			"wrong or blocked PIN",
+			"check the retry counters with: gpg --card-status (unblock needs the admin PIN)",
+		}
+	default:
+		return signingDiagnosis{
+			"not one of the usual suspects",
+			"read the gpg output below, or restart the agent: gpgconf --kill gpg-agent",
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+func init() {
+	register(&command{
+		name:    "wiki-drift",
+		summary: "diff the onboarding wiki page against the implemented steps",
+		run:     runWikiDrift,
+	})
+}
+
+// The wiki and the tool drift apart (they already disagreed on
+// keyservers once). This maintenance command pulls the canonical page
+// and reports both directions of drift with a CI-friendly exit code.
+
+func runWikiDrift(args []string) error {
+	fs := flag.NewFlagSet("wiki-drift", flag.ContinueOnError)
+	url := fs.String("url", org().WikiURL, "wiki page to compare against")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *url == "" {
+		return fmt.Errorf("no wiki URL configured")
+	}
+	resp, err := http.Get(*url)
+	if err != nil {
+		return fmt.Errorf("fetch %s: %w", *url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch %s: HTTP %s", *url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	drift := diffWikiAgainstSteps(string(body))
+	for _, d := range drift {
+		fmt.Println("DRIFT: " + d)
+	}
+	if len(drift) > 0 {
+		return fmt.Errorf("%d drift item(s) between wiki and tool", len(drift))
+	}
+	fmt.Println("wiki and tool agree")
+	return nil
+}
+
+var wikiStepHeading = regexp.MustCompile(`(?m)^#{2,3}\s+(.+)$`)
+var wikiKeyserver = regexp.MustCompile(`(?i)keyserver\s+(\S+)|hkps?://\S+`)
+
+// diffWikiAgainstSteps extracts step headings and keyserver mentions
+// from the wiki markdown and compares them with what the tool does.
+func diffWikiAgainstSteps(page string) []string {
+	var drift []string
+
+	// Step coverage: every tool step should appear somewhere in the
+	// page, and every documented step heading should map to a tool step.
+	lower := strings.ToLower(page)
+	for _, s := range steps {
+		token := strings.ToLower(strings.ReplaceAll(s.name, "-", " "))
+		if !strings.Contains(lower, strings.ToLower(s.name)) && !strings.Contains(lower, token) {
+			drift = append(drift, fmt.Sprintf("tool step %q is not documented in the wiki", s.name))
+		}
+	}
+	for _, m := range wikiStepHeading.FindAllStringSubmatch(page, -1) {
+		heading := strings.TrimSpace(m[1])
+		if !headingMatchesAnyStep(heading) {
+			drift = append(drift, fmt.Sprintf("wiki section %q has no matching tool step", heading))
+		}
+	}
+
+	// Keyserver agreement: the wiki must name the keyserver publish uses.
+	for _, m := range wikiKeyserver.FindAllString(page, -1) {
+		if !strings.Contains(m, "keys.openpgp.org") {
+			drift = append(drift, fmt.Sprintf("wiki names keyserver %q but the tool publishes to keys.openpgp.org", m))
+		}
+	}
+	return drift
+}
+
+func headingMatchesAnyStep(heading string) bool {
+	h := strings.ToLower(heading)
+	for _, s := range steps {
+		name := strings.ToLower(s.name)
+		if strings.Contains(h, name) || strings.Contains(h, strings.ReplaceAll(name, "-", " ")) {
+			return true
+		}
+		// match on a significant word of the description too
+		for _, w := range strings.Fields(strings.ToLower(s.desc)) {
+			if len(w) > 5 && strings.Contains(h, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+func init() {
+	registerStep(&step{
+		name:      "windows",
+		desc:      "Wire gpg-agent into PuTTY and Windows OpenSSH (Windows only)",
+		optional:  false,
+		exclusive: true,
+		explain: "On Windows there is no Unix agent socket: gpg-agent instead " +
+			"serves PuTTY's Pageant interface and the OpenSSH named pipe. This " +
+			"step turns both on and hands the pipe over from the stock ssh-agent " +
+			"service.",
+		run: windowsStep,
+	})
+}
+
+// openSSHAgentPipe is the named pipe Windows' bundled OpenSSH client
+// looks for; with enable-win32-openssh-support gpg-agent serves it.
+const openSSHAgentPipe = `\\.\pipe\openssh-ssh-agent`
+
+// gpg4winInstalled spots a Gpg4win installation even when its bin dir
+// is not on PATH yet (the installer only updates PATH for new shells).
+func gpg4winInstalled() bool {
+	for _, env := range []string{"ProgramFiles(x86)", "ProgramFiles"} {
+		root := os.Getenv(env)
+		if root == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, "Gpg4win")); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// windowsStep configures the Gpg4win agent for SSH use. Elsewhere this
+// is the gui-env/shell-env job; on Windows the moving parts are the
+// Pageant interface, the OpenSSH pipe, and the stock ssh-agent service
+// squatting on that pipe.
+func windowsStep(ctx *setupContext) error {
+	if runtime.GOOS != "windows" {
+		return nil
+	}
+	if !haveExec("gpg") && !gpg4winInstalled() {
+		return fmt.Errorf("Gpg4win is not installed; get it from gpg4win.org and rerun")
+	}
+
+	conf := filepath.Join(gpgDirs().Home, "gpg-agent.conf")
+	if err := os.MkdirAll(gpgDirs().Home, 0o700); err != nil {
+		return err
+	}
+	for _, line := range []string{"enable-putty-support", "enable-win32-openssh-support"} {
+		if err := appendConfigLine(conf, line); err != nil {
+			return err
+		}
+	}
+	fmt.Println("  gpg-agent.conf: PuTTY and Win32-OpenSSH support enabled")
+
+	// The bundled ssh-agent service owns the pipe when it is running;
+	// gpg-agent cannot bind it until the service is out of the way.
+	if _, err := output("powershell", "-NoProfile", "-Command",
+		"Stop-Service ssh-agent -ErrorAction SilentlyContinue; Set-Service ssh-agent -StartupType Disabled"); err != nil {
+		fmt.Printf("  %s could not stop the ssh-agent service (needs an elevated shell); gpg-agent cannot take the pipe until it is disabled\n", sym().warn)
+	} else {
+		fmt.Println("  Windows ssh-agent service stopped and disabled")
+	}
+
+	output("gpgconf", "--kill", "gpg-agent")
+	if _, err := output("gpg-connect-agent", "/bye"); err != nil {
+		return fmt.Errorf("gpg-agent did not come back up: %w", err)
+	}
+	fmt.Println("  ssh now talks to the card via " + openSSHAgentPipe)
+	fmt.Println("  no SSH_AUTH_SOCK is needed; the OpenSSH client finds the pipe by name")
+	return nil
+}